@@ -0,0 +1,76 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoint
+
+import (
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltBucketName = []byte("checkpoint")
+var boltKeyName = []byte("current")
+
+// BoltStore persists a Checkpoint in a BoltDB file. It's the recommended
+// Store implementation for long-running, single-writer deployments
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens (creating if necessary) a BoltDB file at the given path
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucketName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (b *BoltStore) Load() (*Checkpoint, error) {
+	var cp *Checkpoint
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltBucketName).Get(boltKeyName)
+		if data == nil {
+			return nil
+		}
+		cp = &Checkpoint{}
+		return json.Unmarshal(data, cp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+func (b *BoltStore) Save(cp Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucketName).Put(boltKeyName, data)
+	})
+}
+
+func (b *BoltStore) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,34 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package checkpoint provides pluggable persistence for the last fully
+// processed chain position, so a pipeline that's restarted picks up where
+// it left off instead of re-syncing from genesis
+package checkpoint
+
+// Checkpoint records the last slot/hash that the pipeline has fully emitted
+type Checkpoint struct {
+	Slot uint64
+	Hash string
+}
+
+// Store persists and retrieves the current Checkpoint
+type Store interface {
+	// Load returns the last saved Checkpoint, or nil if none has been saved yet
+	Load() (*Checkpoint, error)
+	// Save persists the given Checkpoint, replacing any previously saved value
+	Save(Checkpoint) error
+	// Close releases any resources held by the store
+	Close() error
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+// FileStore persists a Checkpoint as a single JSON file. It's the simplest
+// Store implementation and is primarily useful for local development and
+// single-instance deployments
+type FileStore struct {
+	mutex sync.Mutex
+	path  string
+}
+
+// NewFileStore returns a FileStore backed by the given path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path: path,
+	}
+}
+
+func (f *FileStore) Load() (*Checkpoint, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (f *FileStore) Save(cp Checkpoint) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, data, 0644)
+}
+
+func (f *FileStore) Close() error {
+	return nil
+}
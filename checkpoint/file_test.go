@@ -0,0 +1,74 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStoreLoadMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	store := NewFileStore(path)
+	cp, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load returned unexpected error: %v", err)
+	}
+	if cp != nil {
+		t.Fatalf("Load on missing file = %+v, want nil", cp)
+	}
+}
+
+func TestFileStoreSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	store := NewFileStore(path)
+	want := Checkpoint{Slot: 12345, Hash: "abcdef"}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got == nil || *got != want {
+		t.Fatalf("Load = %+v, want %+v", got, want)
+	}
+}
+
+func TestFileStoreSaveOverwrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	store := NewFileStore(path)
+	if err := store.Save(Checkpoint{Slot: 1, Hash: "aaaa"}); err != nil {
+		t.Fatalf("first Save failed: %v", err)
+	}
+	want := Checkpoint{Slot: 2, Hash: "bbbb"}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("second Save failed: %v", err)
+	}
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got == nil || *got != want {
+		t.Fatalf("Load after overwrite = %+v, want %+v", got, want)
+	}
+}
+
+func TestFileStoreClose(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close returned unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoint
+
+import (
+	"database/sql"
+	"errors"
+
+	_ "modernc.org/sqlite"
+)
+
+// SqliteStore persists a Checkpoint in a SQLite database file
+type SqliteStore struct {
+	db *sql.DB
+}
+
+// NewSqliteStore opens (creating if necessary) a SQLite database at the given path
+func NewSqliteStore(path string) (*SqliteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(
+		`CREATE TABLE IF NOT EXISTS checkpoint (
+			id INTEGER PRIMARY KEY CHECK (id = 0),
+			slot INTEGER NOT NULL,
+			hash TEXT NOT NULL
+		)`,
+	); err != nil {
+		return nil, err
+	}
+	return &SqliteStore{db: db}, nil
+}
+
+func (s *SqliteStore) Load() (*Checkpoint, error) {
+	var cp Checkpoint
+	row := s.db.QueryRow(`SELECT slot, hash FROM checkpoint WHERE id = 0`)
+	if err := row.Scan(&cp.Slot, &cp.Hash); errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (s *SqliteStore) Save(cp Checkpoint) error {
+	_, err := s.db.Exec(
+		`INSERT INTO checkpoint (id, slot, hash) VALUES (0, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET slot = excluded.slot, hash = excluded.hash`,
+		cp.Slot,
+		cp.Hash,
+	)
+	return err
+}
+
+func (s *SqliteStore) Close() error {
+	return s.db.Close()
+}
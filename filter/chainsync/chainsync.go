@@ -15,20 +15,30 @@
 package chainsync
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/google/cel-go/cel"
+
 	"github.com/blinklabs-io/gouroboros/ledger"
 	"github.com/blinklabs-io/snek/event"
 	"github.com/blinklabs-io/snek/input/chainsync"
+	"github.com/blinklabs-io/snek/metrics"
 )
 
 type ChainSync struct {
 	errorChan               chan error
 	inputChan               chan event.Event
 	outputChan              chan event.Event
-	filterAddresses         []string
-	filterPolicyIds         []string
-	filterAssetFingerprints []string
+	filterAddresses         map[string]struct{}
+	filterPolicyIds         map[string]struct{}
+	filterAssetFingerprints map[string]struct{}
+	filterDatumHashes       map[string]struct{}
+	filterScriptHashes      map[string]struct{}
+	filterMetadataLabels    map[uint64]struct{}
+	filterExpressions       []string
+	celEnv                  *cel.Env
+	celPrograms             []cel.Program
 }
 
 // New returns a new ChainSync object with the specified options applied
@@ -46,108 +56,42 @@ func New(options ...ChainSyncOptionFunc) *ChainSync {
 
 // Start the chain sync filter
 func (c *ChainSync) Start() error {
+	if len(c.filterExpressions) > 0 {
+		env, err := cel.NewEnv(
+			cel.Variable("tx", cel.DynType),
+			cel.Variable("block", cel.DynType),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create CEL environment: %w", err)
+		}
+		c.celEnv = env
+		for _, expr := range c.filterExpressions {
+			ast, issues := env.Compile(expr)
+			if issues != nil && issues.Err() != nil {
+				return fmt.Errorf("failed to compile filter expression %q: %w", expr, issues.Err())
+			}
+			prg, err := env.Program(ast)
+			if err != nil {
+				return fmt.Errorf("failed to build filter program for %q: %w", expr, err)
+			}
+			c.celPrograms = append(c.celPrograms, prg)
+		}
+	}
 	go func() {
-		// TODO: pre-process filter params to be more useful for direct comparison
 		for {
 			evt, ok := <-c.inputChan
 			// Channel has been closed, which means we're shutting down
 			if !ok {
 				return
 			}
+			metrics.ObserveEvent(evt)
 			switch v := evt.Payload.(type) {
 			case chainsync.TransactionEvent:
-				// Check address filter
-				if len(c.filterAddresses) > 0 {
-					filterMatched := false
-					for _, filterAddress := range c.filterAddresses {
-						isStakeAddress := strings.HasPrefix(filterAddress, "stake")
-						foundMatch := false
-						for _, output := range v.Outputs {
-							if output.Address().String() == filterAddress {
-								foundMatch = true
-								break
-							}
-							if isStakeAddress {
-								stakeAddr := output.Address().StakeAddress()
-								if stakeAddr == nil {
-									continue
-								}
-								if stakeAddr.String() == filterAddress {
-									foundMatch = true
-									break
-								}
-							}
-						}
-						if foundMatch {
-							filterMatched = true
-							break
-						}
-					}
-					// Skip the event if none of the filter values matched
-					if !filterMatched {
-						continue
-					}
-				}
-				// Check policy ID filter
-				if len(c.filterPolicyIds) > 0 {
-					filterMatched := false
-					for _, filterPolicyId := range c.filterPolicyIds {
-						foundMatch := false
-						for _, output := range v.Outputs {
-							if output.Assets() != nil {
-								for _, policyId := range output.Assets().Policies() {
-									if policyId.String() == filterPolicyId {
-										foundMatch = true
-										break
-									}
-								}
-							}
-							if foundMatch {
-								break
-							}
-						}
-						if foundMatch {
-							filterMatched = true
-							break
-						}
-					}
-					// Skip the event if none of the filter values matched
-					if !filterMatched {
-						continue
-					}
+				if !c.matchesLegacyFilters(v) {
+					continue
 				}
-				// Check asset fingerprint filter
-				if len(c.filterAssetFingerprints) > 0 {
-					filterMatched := false
-					for _, filterAssetFingerprint := range c.filterAssetFingerprints {
-						foundMatch := false
-						for _, output := range v.Outputs {
-							if output.Assets() != nil {
-								for _, policyId := range output.Assets().Policies() {
-									for _, assetName := range output.Assets().Assets(policyId) {
-										assetFp := ledger.NewAssetFingerprint(policyId.Bytes(), assetName)
-										if assetFp.String() == filterAssetFingerprint {
-											foundMatch = true
-										}
-									}
-									if foundMatch {
-										break
-									}
-								}
-								if foundMatch {
-									break
-								}
-							}
-						}
-						if foundMatch {
-							filterMatched = true
-							break
-						}
-					}
-					// Skip the event if none of the filter values matched
-					if !filterMatched {
-						continue
-					}
+				if !c.matchesExpressionFilters(v) {
+					continue
 				}
 			}
 			c.outputChan <- evt
@@ -156,6 +100,185 @@ func (c *ChainSync) Start() error {
 	return nil
 }
 
+// matchesLegacyFilters applies the address/policy-ID/asset-fingerprint/
+// datum-hash/script-hash/metadata-label filters. An empty filter set of a given dimension
+// always matches
+func (c *ChainSync) matchesLegacyFilters(v chainsync.TransactionEvent) bool {
+	dimensions := []struct {
+		name    string
+		active  bool
+		matches func(chainsync.TransactionEvent) bool
+	}{
+		{"address", len(c.filterAddresses) > 0, c.matchesAddressFilter},
+		{"policy_id", len(c.filterPolicyIds) > 0, c.matchesPolicyIdFilter},
+		{"asset_fingerprint", len(c.filterAssetFingerprints) > 0, c.matchesAssetFingerprintFilter},
+		{"datum_hash", len(c.filterDatumHashes) > 0, c.matchesDatumHashFilter},
+		{"script_hash", len(c.filterScriptHashes) > 0, c.matchesScriptHashFilter},
+		{"metadata_label", len(c.filterMetadataLabels) > 0, c.matchesMetadataLabelFilter},
+	}
+	for _, dimension := range dimensions {
+		if !dimension.active {
+			continue
+		}
+		if dimension.matches(v) {
+			metrics.FilterEventsTotal.WithLabelValues(dimension.name, metrics.FilterResultPassed).Inc()
+		} else {
+			metrics.FilterEventsTotal.WithLabelValues(dimension.name, metrics.FilterResultDropped).Inc()
+			return false
+		}
+	}
+	return true
+}
+
+func (c *ChainSync) matchesAddressFilter(v chainsync.TransactionEvent) bool {
+	for _, output := range v.Outputs {
+		addr := output.Address().String()
+		if _, ok := c.filterAddresses[addr]; ok {
+			return true
+		}
+		if stakeAddr := output.Address().StakeAddress(); stakeAddr != nil {
+			if _, ok := c.filterAddresses[stakeAddr.String()]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *ChainSync) matchesPolicyIdFilter(v chainsync.TransactionEvent) bool {
+	for _, output := range v.Outputs {
+		if output.Assets() == nil {
+			continue
+		}
+		for _, policyId := range output.Assets().Policies() {
+			if _, ok := c.filterPolicyIds[policyId.String()]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *ChainSync) matchesAssetFingerprintFilter(v chainsync.TransactionEvent) bool {
+	for _, output := range v.Outputs {
+		if output.Assets() == nil {
+			continue
+		}
+		for _, policyId := range output.Assets().Policies() {
+			for _, assetName := range output.Assets().Assets(policyId) {
+				assetFp := ledger.NewAssetFingerprint(policyId.Bytes(), assetName)
+				if _, ok := c.filterAssetFingerprints[assetFp.String()]; ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (c *ChainSync) matchesDatumHashFilter(v chainsync.TransactionEvent) bool {
+	for _, output := range v.Outputs {
+		datumHash := output.DatumHash()
+		if datumHash == nil {
+			continue
+		}
+		if _, ok := c.filterDatumHashes[datumHash.String()]; ok {
+			return true
+		}
+	}
+	for datumHash := range v.Datums {
+		if _, ok := c.filterDatumHashes[datumHash]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ChainSync) matchesScriptHashFilter(v chainsync.TransactionEvent) bool {
+	for _, script := range v.ReferenceScripts {
+		if _, ok := c.filterScriptHashes[script.Hash]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ChainSync) matchesMetadataLabelFilter(v chainsync.TransactionEvent) bool {
+	if v.Metadata == nil {
+		return false
+	}
+	for label := range c.filterMetadataLabels {
+		if _, ok := v.Metadata.Value().(map[uint64]interface{})[label]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesExpressionFilters evaluates the compiled CEL programs against a
+// structured activation for the event. All configured expressions must
+// evaluate to true for the event to pass
+func (c *ChainSync) matchesExpressionFilters(v chainsync.TransactionEvent) bool {
+	if len(c.celPrograms) == 0 {
+		return true
+	}
+	activation := map[string]interface{}{
+		"tx":    transactionEventToCelMap(v),
+		"block": map[string]interface{}{"slot": v.SlotNumber},
+	}
+	for _, prg := range c.celPrograms {
+		out, _, err := prg.Eval(activation)
+		if err != nil {
+			c.errorChan <- fmt.Errorf("failed to evaluate filter expression: %w", err)
+			return false
+		}
+		matched, ok := out.Value().(bool)
+		if !ok || !matched {
+			metrics.FilterEventsTotal.WithLabelValues("expression", metrics.FilterResultDropped).Inc()
+			return false
+		}
+	}
+	metrics.FilterEventsTotal.WithLabelValues("expression", metrics.FilterResultPassed).Inc()
+	return true
+}
+
+func transactionEventToCelMap(v chainsync.TransactionEvent) map[string]interface{} {
+	outputs := make([]interface{}, len(v.Outputs))
+	for i, output := range v.Outputs {
+		assets := map[string]interface{}{}
+		if output.Assets() != nil {
+			for _, policyId := range output.Assets().Policies() {
+				names := make([]string, 0)
+				for _, assetName := range output.Assets().Assets(policyId) {
+					names = append(names, string(assetName))
+				}
+				assets[policyId.String()] = names
+			}
+		}
+		outputs[i] = map[string]interface{}{
+			"address": output.Address().String(),
+			"assets":  assets,
+		}
+	}
+	inputs := make([]interface{}, len(v.Inputs))
+	for i, input := range v.Inputs {
+		inputs[i] = map[string]interface{}{
+			"id":    strings.ToLower(input.Id().String()),
+			"index": input.Index(),
+		}
+	}
+	var metadata interface{}
+	if v.Metadata != nil {
+		metadata = v.Metadata.Value()
+	}
+	return map[string]interface{}{
+		"hash":     v.TransactionHash,
+		"inputs":   inputs,
+		"outputs":  outputs,
+		"metadata": metadata,
+	}
+}
+
 // Stop the chain sync filter
 func (c *ChainSync) Stop() error {
 	close(c.inputChan)
@@ -0,0 +1,82 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+type ChainSyncOptionFunc func(*ChainSync)
+
+// WithFilterAddresses sets the payment/stake addresses to filter transactions by
+func WithFilterAddresses(addresses []string) ChainSyncOptionFunc {
+	return func(c *ChainSync) {
+		c.filterAddresses = toStringSet(addresses)
+	}
+}
+
+// WithFilterPolicyIds sets the asset policy IDs to filter transactions by
+func WithFilterPolicyIds(policyIds []string) ChainSyncOptionFunc {
+	return func(c *ChainSync) {
+		c.filterPolicyIds = toStringSet(policyIds)
+	}
+}
+
+// WithFilterAssetFingerprints sets the asset fingerprints to filter transactions by
+func WithFilterAssetFingerprints(assetFingerprints []string) ChainSyncOptionFunc {
+	return func(c *ChainSync) {
+		c.filterAssetFingerprints = toStringSet(assetFingerprints)
+	}
+}
+
+// WithFilterDatumHashes sets the datum hashes to filter transactions by,
+// matched against output datum hashes and the transaction's datums
+func WithFilterDatumHashes(datumHashes []string) ChainSyncOptionFunc {
+	return func(c *ChainSync) {
+		c.filterDatumHashes = toStringSet(datumHashes)
+	}
+}
+
+// WithFilterScriptHashes sets the reference-script hashes to filter transactions by
+func WithFilterScriptHashes(scriptHashes []string) ChainSyncOptionFunc {
+	return func(c *ChainSync) {
+		c.filterScriptHashes = toStringSet(scriptHashes)
+	}
+}
+
+// WithFilterMetadataLabels sets the transaction metadata labels to filter by
+func WithFilterMetadataLabels(labels []uint64) ChainSyncOptionFunc {
+	return func(c *ChainSync) {
+		set := make(map[uint64]struct{}, len(labels))
+		for _, label := range labels {
+			set[label] = struct{}{}
+		}
+		c.filterMetadataLabels = set
+	}
+}
+
+// WithFilterExpression adds a CEL expression that a transaction must satisfy
+// to pass the filter. The expression is evaluated against a structured
+// activation exposing `tx` (hash, inputs, outputs, metadata) and `block`
+// (slot). May be specified more than once; all expressions must match
+func WithFilterExpression(expr string) ChainSyncOptionFunc {
+	return func(c *ChainSync) {
+		c.filterExpressions = append(c.filterExpressions, expr)
+	}
+}
+
+func toStringSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[value] = struct{}{}
+	}
+	return set
+}
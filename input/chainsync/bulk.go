@@ -0,0 +1,262 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"encoding/hex"
+	"time"
+
+	ouroboros "github.com/blinklabs-io/gouroboros"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	"github.com/blinklabs-io/gouroboros/protocol/blockfetch"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+
+	"github.com/blinklabs-io/snek/event"
+)
+
+// bulkShard describes a contiguous, fetchable sub-range of the overall bulk
+// sync range. Since blockfetch's GetBlockRange requires a known point (slot
+// + hash) at both ends, shard boundaries other than the very first and last
+// are discovered via a single lightweight header-only chain-sync pass before
+// any of the shards are fetched
+type bulkShard struct {
+	index int
+	start ocommon.Point
+	end   ocommon.Point
+}
+
+// startBulkFetchParallel splits [bulkRangeStart, bulkRangeEnd] into shards of
+// bulkShardSize slots, fetches each shard concurrently (bounded to
+// bulkWorkers workers) over its own ouroboros.Connection, and reassembles
+// the resulting block/transaction events into c.eventChan in strict slot
+// order via a per-shard reorder buffer
+func (c *ChainSync) startBulkFetchParallel() error {
+	shards, err := c.planBulkShards()
+	if err != nil {
+		return err
+	}
+	// Each shard gets its own buffered channel. A single drain goroutine
+	// reads them strictly in shard order, so a fast shard N+1 can finish
+	// fetching (and block on a full channel) while we're still draining
+	// shard N without ever emitting out of order
+	shardChans := make([]chan event.Event, len(shards))
+	for i := range shards {
+		shardChans[i] = make(chan event.Event, 256)
+	}
+	go c.drainBulkShards(shardChans)
+
+	sem := make(chan struct{}, c.bulkWorkers)
+	go func() {
+		for _, shard := range shards {
+			shard := shard
+			sem <- struct{}{}
+			go func() {
+				defer func() { <-sem }()
+				defer close(shardChans[shard.index])
+				if err := c.fetchBulkShard(shard, shardChans[shard.index]); err != nil {
+					c.errorChan <- err
+				}
+			}()
+		}
+	}()
+	return nil
+}
+
+// planBulkShards divides the bulk range into fixed-size slot shards and
+// resolves the (slot, hash) point at each interior boundary by running a
+// single header-only chain-sync pass across the full range on a dedicated
+// connection
+func (c *ChainSync) planBulkShards() ([]bulkShard, error) {
+	startSlot := c.bulkRangeStart.Slot
+	endSlot := c.bulkRangeEnd.Slot
+	boundaries := []ocommon.Point{c.bulkRangeStart}
+	if endSlot > startSlot+c.bulkShardSize {
+		points, err := c.collectShardBoundaryPoints(startSlot, endSlot)
+		if err != nil {
+			return nil, err
+		}
+		boundaries = append(boundaries, points...)
+	}
+	// collectShardBoundaryPoints stops at the first header whose slot is
+	// already >= endSlot, so its last point is almost always bulkRangeEnd
+	// itself. Drop it here rather than appending bulkRangeEnd a second time,
+	// which would otherwise produce a final shard with start == end that
+	// GetBlockRange fetches zero blocks for and never completes
+	if len(boundaries) > 0 && boundaries[len(boundaries)-1].Slot >= endSlot {
+		boundaries = boundaries[:len(boundaries)-1]
+	}
+	boundaries = append(boundaries, c.bulkRangeEnd)
+	return shardsFromBoundaries(boundaries), nil
+}
+
+// shardsFromBoundaries turns a slice of ordered boundary points into
+// bulkShards covering [boundaries[0], boundaries[len-1]]. Adjacent shards
+// share a boundary point, since GetBlockRange requires a known point at
+// both ends; fetchBulkShard skips re-emitting a shard's start block for
+// every shard but the first, so that shared point is only ever emitted once
+func shardsFromBoundaries(boundaries []ocommon.Point) []bulkShard {
+	shards := make([]bulkShard, 0, len(boundaries)-1)
+	for i := 0; i < len(boundaries)-1; i++ {
+		if boundaries[i].Slot == boundaries[i+1].Slot {
+			continue
+		}
+		shards = append(shards, bulkShard{
+			start: boundaries[i],
+			end:   boundaries[i+1],
+		})
+	}
+	for i := range shards {
+		shards[i].index = i
+	}
+	return shards
+}
+
+// collectShardBoundaryPoints walks block headers (not full blocks) from
+// startSlot to endSlot on a dedicated connection, recording a boundary point
+// every bulkShardSize slots
+func (c *ChainSync) collectShardBoundaryPoints(startSlot, endSlot uint64) ([]ocommon.Point, error) {
+	var points []ocommon.Point
+	nextBoundary := startSlot + c.bulkShardSize
+	doneChan := make(chan error, 1)
+
+	oConn, err := ouroboros.NewConnection(
+		ouroboros.WithNetworkMagic(c.networkMagic),
+		ouroboros.WithNodeToNode(c.useNtn),
+		ouroboros.WithKeepAlive(true),
+		ouroboros.WithChainSyncConfig(
+			ochainsync.NewConfig(
+				ochainsync.WithRollForwardFunc(func(blockType uint, blockData interface{}, tip ochainsync.Tip) error {
+					header, ok := blockData.(ledger.BlockHeader)
+					if !ok {
+						return nil
+					}
+					if header.SlotNumber() >= nextBoundary || header.SlotNumber() >= endSlot {
+						hash, err := hex.DecodeString(header.Hash())
+						if err != nil {
+							return err
+						}
+						points = append(points, ocommon.Point{Slot: header.SlotNumber(), Hash: hash})
+						nextBoundary += c.bulkShardSize
+					}
+					if header.SlotNumber() >= endSlot {
+						doneChan <- nil
+					}
+					return nil
+				}),
+				ochainsync.WithRollBackwardFunc(func(point ocommon.Point, tip ochainsync.Tip) error {
+					return nil
+				}),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer oConn.Close()
+	if err := oConn.Dial(c.dialFamily, c.dialAddress); err != nil {
+		return nil, err
+	}
+	oConn.ChainSync().Client.Start()
+	if err := oConn.ChainSync().Client.Sync([]ocommon.Point{c.bulkRangeStart}); err != nil {
+		return nil, err
+	}
+	select {
+	case err := <-doneChan:
+		return points, err
+	case err := <-oConn.ErrorChan():
+		return nil, err
+	}
+}
+
+// shardSkipsBlock reports whether fetchBulkShard should skip emitting a
+// block at blockSlot for the given shard. GetBlockRange(shard.start,
+// shard.end) is inclusive of both endpoints, and shard.start for every
+// shard but the first is exactly the previous shard's shard.end, so
+// emitting it here too would duplicate a block (and all its transactions)
+// that the previous shard already emitted
+func shardSkipsBlock(shard bulkShard, blockSlot uint64) bool {
+	return shard.index > 0 && blockSlot == shard.start.Slot
+}
+
+// fetchBulkShard fetches a single shard's full blocks/transactions over a
+// new connection and writes the resulting events to out in slot order
+func (c *ChainSync) fetchBulkShard(shard bulkShard, out chan<- event.Event) error {
+	doneChan := make(chan error, 1)
+	oConn, err := ouroboros.NewConnection(
+		ouroboros.WithNetworkMagic(c.networkMagic),
+		ouroboros.WithNodeToNode(c.useNtn),
+		ouroboros.WithKeepAlive(true),
+		ouroboros.WithBlockFetchConfig(
+			blockfetch.NewConfig(
+				blockfetch.WithBlockFunc(func(block ledger.Block) error {
+					if shardSkipsBlock(shard, block.SlotNumber()) {
+						if block.SlotNumber() >= shard.end.Slot {
+							doneChan <- nil
+						}
+						return nil
+					}
+					out <- event.New("chainsync.block", time.Now(), NewBlockEvent(block, c.includeCbor))
+					for _, transaction := range block.Transactions() {
+						txPayload := NewTransactionEvent(block, transaction, c.includeCbor)
+						out <- event.New("chainsync.transaction", time.Now(), txPayload)
+						for _, scriptEvt := range newScriptInvocationEvents(txPayload) {
+							out <- scriptEvt
+						}
+					}
+					if block.SlotNumber() >= shard.end.Slot {
+						doneChan <- nil
+					}
+					return nil
+				}),
+			),
+		),
+	)
+	if err != nil {
+		return err
+	}
+	defer oConn.Close()
+	if err := oConn.Dial(c.dialFamily, c.dialAddress); err != nil {
+		return err
+	}
+	oConn.BlockFetch().Client.Start()
+	if err := oConn.BlockFetch().Client.GetBlockRange(shard.start, shard.end); err != nil {
+		return err
+	}
+	select {
+	case err := <-doneChan:
+		return err
+	case err := <-oConn.ErrorChan():
+		return err
+	}
+}
+
+// drainBulkShards emits events from each shard channel strictly in shard
+// order, updating status/checkpoint as each block event passes through, and
+// falls through to normal (non-bulk) chain-sync once the last shard drains
+func (c *ChainSync) drainBulkShards(shardChans []chan event.Event) {
+	for _, ch := range shardChans {
+		for evt := range ch {
+			c.eventChan <- evt
+			if blockEvt, ok := evt.Payload.(BlockEvent); ok {
+				c.updateStatus(blockEvt.SlotNumber, blockEvt.BlockNumber, blockEvt.BlockHash, c.bulkRangeEnd.Slot, hex.EncodeToString(c.bulkRangeEnd.Hash))
+				c.saveCheckpoint(blockEvt.SlotNumber, blockEvt.BlockHash)
+			}
+		}
+	}
+	if err := c.oConn.ChainSync().Client.Sync([]ocommon.Point{c.bulkRangeEnd}); err != nil {
+		c.errorChan <- err
+	}
+}
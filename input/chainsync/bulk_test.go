@@ -0,0 +1,106 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"testing"
+
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+func point(slot uint64) ocommon.Point {
+	return ocommon.Point{Slot: slot}
+}
+
+func TestShardsFromBoundaries(t *testing.T) {
+	boundaries := []ocommon.Point{point(0), point(100), point(200), point(300)}
+	shards := shardsFromBoundaries(boundaries)
+	if len(shards) != 3 {
+		t.Fatalf("len(shards) = %d, want 3", len(shards))
+	}
+	for i, want := range []bulkShard{
+		{index: 0, start: point(0), end: point(100)},
+		{index: 1, start: point(100), end: point(200)},
+		{index: 2, start: point(200), end: point(300)},
+	} {
+		if shards[i] != want {
+			t.Fatalf("shards[%d] = %+v, want %+v", i, shards[i], want)
+		}
+	}
+}
+
+func TestShardsFromBoundariesDropsZeroWidthShards(t *testing.T) {
+	// A duplicated boundary (e.g. the final one coinciding with bulkRangeEnd)
+	// must not produce a start == end shard
+	boundaries := []ocommon.Point{point(0), point(100), point(100)}
+	shards := shardsFromBoundaries(boundaries)
+	if len(shards) != 1 {
+		t.Fatalf("len(shards) = %d, want 1", len(shards))
+	}
+	if shards[0] != (bulkShard{index: 0, start: point(0), end: point(100)}) {
+		t.Fatalf("shards[0] = %+v, want {index:0 start:0 end:100}", shards[0])
+	}
+}
+
+func TestShardSkipsBlock(t *testing.T) {
+	tests := []struct {
+		name  string
+		shard bulkShard
+		slot  uint64
+		want  bool
+	}{
+		{"first shard's own start is never skipped", bulkShard{index: 0, start: point(0), end: point(100)}, 0, false},
+		{"interior shard's start duplicates the previous shard's end", bulkShard{index: 1, start: point(100), end: point(200)}, 100, true},
+		{"interior shard's end is not skipped", bulkShard{index: 1, start: point(100), end: point(200)}, 200, false},
+		{"a block strictly inside the shard is not skipped", bulkShard{index: 1, start: point(100), end: point(200)}, 150, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shardSkipsBlock(tt.shard, tt.slot); got != tt.want {
+				t.Errorf("shardSkipsBlock(%+v, %d) = %v, want %v", tt.shard, tt.slot, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBulkShardFetchEmitsEverySlotExactlyOnce simulates fetching every shard
+// produced by shardsFromBoundaries over a fake chain that has one block per
+// slot, applying the same shardSkipsBlock filter fetchBulkShard does, and
+// asserts the union of what every shard emits covers the full range with no
+// slot duplicated or missing.
+func TestBulkShardFetchEmitsEverySlotExactlyOnce(t *testing.T) {
+	boundaries := []ocommon.Point{point(1000), point(1100), point(1200), point(1300), point(1300)}
+	shards := shardsFromBoundaries(boundaries)
+	if len(shards) != 3 {
+		t.Fatalf("len(shards) = %d, want 3", len(shards))
+	}
+
+	emitted := make(map[uint64]int)
+	for _, shard := range shards {
+		// GetBlockRange(shard.start, shard.end) is inclusive of both ends
+		for slot := shard.start.Slot; slot <= shard.end.Slot; slot++ {
+			if shardSkipsBlock(shard, slot) {
+				continue
+			}
+			emitted[slot]++
+		}
+	}
+
+	for slot := boundaries[0].Slot; slot <= boundaries[len(boundaries)-1].Slot; slot++ {
+		if emitted[slot] != 1 {
+			t.Fatalf("slot %d emitted %d times, want exactly 1", slot, emitted[slot])
+		}
+	}
+}
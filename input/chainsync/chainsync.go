@@ -26,6 +26,8 @@ import (
 	"github.com/blinklabs-io/gouroboros/protocol/blockfetch"
 	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
 	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+
+	"github.com/blinklabs-io/snek/checkpoint"
 )
 
 type ChainSync struct {
@@ -45,15 +47,27 @@ type ChainSync struct {
 	eventChan        chan event.Event
 	bulkRangeStart   ocommon.Point
 	bulkRangeEnd     ocommon.Point
+	bulkWorkers      int
+	bulkShardSize    uint64
+	checkpointStore  checkpoint.Store
+	dialFamily       string
+	dialAddress      string
+	useNtn           bool
+	confirmations    uint
+	currentGroup     *blockGroup
+	pendingBlocks    []*blockGroup
+	releasedBlocks   []*blockGroup
 }
 
 type ChainSyncStatus struct {
-	SlotNumber    uint64
-	BlockNumber   uint64
-	BlockHash     string
-	TipSlotNumber uint64
-	TipBlockHash  string
-	TipReached    bool
+	SlotNumber        uint64
+	BlockNumber       uint64
+	BlockHash         string
+	TipSlotNumber     uint64
+	TipBlockHash      string
+	TipReached        bool
+	BulkRangeStartSlot uint64
+	BulkRangeEndSlot   uint64
 }
 
 type StatusUpdateFunc func(ChainSyncStatus)
@@ -65,6 +79,8 @@ func New(options ...ChainSyncOptionFunc) *ChainSync {
 		eventChan:       make(chan event.Event, 10),
 		intersectPoints: []ocommon.Point{},
 		status:          &ChainSyncStatus{},
+		bulkWorkers:     1,
+		bulkShardSize:   10000,
 	}
 	for _, option := range options {
 		option(c)
@@ -74,6 +90,19 @@ func New(options ...ChainSyncOptionFunc) *ChainSync {
 
 // Start the chain sync input
 func (c *ChainSync) Start() error {
+	if c.checkpointStore != nil {
+		cp, err := c.checkpointStore.Load()
+		if err != nil {
+			return err
+		}
+		if cp != nil {
+			hash, err := hex.DecodeString(cp.Hash)
+			if err != nil {
+				return err
+			}
+			c.intersectPoints = []ocommon.Point{{Slot: cp.Slot, Hash: hash}}
+		}
+	}
 	if err := c.setupConnection(); err != nil {
 		return err
 	}
@@ -87,8 +116,14 @@ func (c *ChainSync) Start() error {
 		if err != nil {
 			return err
 		}
-		if err := c.oConn.BlockFetch().Client.GetBlockRange(c.bulkRangeStart, c.bulkRangeEnd); err != nil {
-			return err
+		if c.bulkWorkers > 1 {
+			if err := c.startBulkFetchParallel(); err != nil {
+				return err
+			}
+		} else {
+			if err := c.oConn.BlockFetch().Client.GetBlockRange(c.bulkRangeStart, c.bulkRangeEnd); err != nil {
+				return err
+			}
 		}
 	} else {
 		if c.intersectTip {
@@ -162,6 +197,10 @@ func (c *ChainSync) setupConnection() error {
 	} else if dialFamily == "" || dialAddress == "" {
 		return fmt.Errorf("you must specify a host/port, UNIX socket path, or well-known network name")
 	}
+	// Stash resolved dial params so bulk-fetch workers can open their own connections
+	c.dialFamily = dialFamily
+	c.dialAddress = dialAddress
+	c.useNtn = useNtn
 	// Create connection
 	var err error
 	c.oConn, err = ouroboros.NewConnection(
@@ -200,17 +239,23 @@ func (c *ChainSync) setupConnection() error {
 }
 
 func (c *ChainSync) handleRollBackward(point ocommon.Point, tip ochainsync.Tip) error {
+	invalidated := c.handleRollback(point)
 	evt := event.New("chainsync.rollback", time.Now(), NewRollbackEvent(point))
 	c.eventChan <- evt
+	if len(invalidated) > 0 {
+		reorgEvt := event.New("chainsync.reorg", time.Now(), NewReorgEvent(invalidated))
+		c.eventChan <- reorgEvt
+	}
 	return nil
 }
 
 func (c *ChainSync) handleRollForward(blockType uint, blockData interface{}, tip ochainsync.Tip) error {
 	switch v := blockData.(type) {
 	case ledger.Block:
-		evt := event.New("chainsync.block", time.Now(), NewBlockEvent(v, c.includeCbor))
-		c.eventChan <- evt
+		c.beginBlockGroup(v.SlotNumber(), v.Hash())
+		c.bufferGroupEvent(event.New("chainsync.block", time.Now(), NewBlockEvent(v, c.includeCbor)), "")
 		c.updateStatus(v.SlotNumber(), v.BlockNumber(), v.Hash(), tip.Point.Slot, hex.EncodeToString(tip.Point.Hash))
+		c.releaseConfirmed(tip.Point.Slot)
 	case ledger.BlockHeader:
 		blockSlot := v.SlotNumber()
 		blockHash, _ := hex.DecodeString(v.Hash())
@@ -218,13 +263,17 @@ func (c *ChainSync) handleRollForward(blockType uint, blockData interface{}, tip
 		if err != nil {
 			return err
 		}
-		blockEvt := event.New("chainsync.block", time.Now(), NewBlockEvent(block, c.includeCbor))
-		c.eventChan <- blockEvt
+		c.beginBlockGroup(block.SlotNumber(), block.Hash())
+		c.bufferGroupEvent(event.New("chainsync.block", time.Now(), NewBlockEvent(block, c.includeCbor)), "")
 		for _, transaction := range block.Transactions() {
-			txEvt := event.New("chainsync.transaction", time.Now(), NewTransactionEvent(block, transaction, c.includeCbor))
-			c.eventChan <- txEvt
+			txPayload := NewTransactionEvent(block, transaction, c.includeCbor)
+			c.bufferGroupEvent(event.New("chainsync.transaction", time.Now(), txPayload), transaction.Hash())
+			for _, scriptEvt := range newScriptInvocationEvents(txPayload) {
+				c.bufferGroupEvent(scriptEvt, "")
+			}
 		}
 		c.updateStatus(v.SlotNumber(), v.BlockNumber(), v.Hash(), tip.Point.Slot, hex.EncodeToString(tip.Point.Hash))
+		c.releaseConfirmed(tip.Point.Slot)
 	}
 	return nil
 }
@@ -233,10 +282,14 @@ func (c *ChainSync) handleBlockFetchBlock(block ledger.Block) error {
 	blockEvt := event.New("chainsync.block", time.Now(), NewBlockEvent(block, c.includeCbor))
 	c.eventChan <- blockEvt
 	for _, transaction := range block.Transactions() {
-		txEvt := event.New("chainsync.transaction", time.Now(), NewTransactionEvent(block, transaction, c.includeCbor))
-		c.eventChan <- txEvt
+		txPayload := NewTransactionEvent(block, transaction, c.includeCbor)
+		c.eventChan <- event.New("chainsync.transaction", time.Now(), txPayload)
+		for _, scriptEvt := range newScriptInvocationEvents(txPayload) {
+			c.eventChan <- scriptEvt
+		}
 	}
 	c.updateStatus(block.SlotNumber(), block.BlockNumber(), block.Hash(), c.bulkRangeEnd.Slot, hex.EncodeToString(c.bulkRangeEnd.Hash))
+	c.saveCheckpoint(block.SlotNumber(), block.Hash())
 	// Start normal chain-sync if we've reached the last block of our bulk range
 	if block.SlotNumber() == c.bulkRangeEnd.Slot {
 		if err := c.oConn.ChainSync().Client.Sync([]ocommon.Point{c.bulkRangeEnd}); err != nil {
@@ -262,7 +315,26 @@ func (c *ChainSync) updateStatus(slotNumber uint64, blockNumber uint64, blockHas
 	c.status.BlockHash = blockHash
 	c.status.TipSlotNumber = tipSlotNumber
 	c.status.TipBlockHash = tipBlockHash
+	c.status.BulkRangeStartSlot = c.bulkRangeStart.Slot
+	c.status.BulkRangeEndSlot = c.bulkRangeEnd.Slot
 	if c.statusUpdateFunc != nil {
 		c.statusUpdateFunc(*(c.status))
 	}
 }
+
+// saveCheckpoint persists slot/hash as the last fully-emitted chain
+// position, so a restart resumes after it instead of re-emitting it. Bulk
+// fetch emits events directly with no confirmation-depth buffering, so
+// callers on that path (handleBlockFetchBlock, drainBulkShards) call this
+// as soon as a block is processed. The live chain-sync path
+// (handleRollForward) buffers a block's events until releaseConfirmed lets
+// them through, so it calls this from there instead, once the events are
+// actually released
+func (c *ChainSync) saveCheckpoint(slotNumber uint64, blockHash string) {
+	if c.checkpointStore == nil {
+		return
+	}
+	if err := c.checkpointStore.Save(checkpoint.Checkpoint{Slot: slotNumber, Hash: blockHash}); err != nil {
+		c.errorChan <- err
+	}
+}
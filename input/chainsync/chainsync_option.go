@@ -0,0 +1,57 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"github.com/blinklabs-io/snek/checkpoint"
+)
+
+// WithBulkWorkers sets the number of concurrent block-fetch workers used
+// during bulk range sync. A value greater than 1 splits the bulk range into
+// shards of WithBulkShardSize slots and fetches them concurrently over
+// separate connections. Defaults to 1 (the existing serial behavior)
+func WithBulkWorkers(bulkWorkers int) ChainSyncOptionFunc {
+	return func(c *ChainSync) {
+		c.bulkWorkers = bulkWorkers
+	}
+}
+
+// WithBulkShardSize sets the number of slots covered by each bulk-fetch shard
+func WithBulkShardSize(bulkShardSize uint64) ChainSyncOptionFunc {
+	return func(c *ChainSync) {
+		c.bulkShardSize = bulkShardSize
+	}
+}
+
+// WithConfirmations sets the number of slots of lag behind the chain tip
+// that a block must have before its events are released on OutputChan().
+// Buffered events belonging to a block that gets rolled back before it
+// reaches this depth are dropped instead of emitted. Defaults to 0, which
+// preserves the original behavior of releasing events as soon as they're
+// received
+func WithConfirmations(confirmations uint) ChainSyncOptionFunc {
+	return func(c *ChainSync) {
+		c.confirmations = confirmations
+	}
+}
+
+// WithCheckpointStore sets the checkpoint.Store used to persist and resume
+// bulk sync progress. When set, Start() consults it for a resume point
+// before computing the bulk range, and it's updated as shards are emitted
+func WithCheckpointStore(store checkpoint.Store) ChainSyncOptionFunc {
+	return func(c *ChainSync) {
+		c.checkpointStore = store
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+
+	"github.com/blinklabs-io/snek/event"
+)
+
+// releasedHistoryLimit bounds how many already-released blocks we remember
+// for reorg reporting, so a rollback far deeper than any realistic
+// confirmation depth doesn't grow this unbounded
+const releasedHistoryLimit = 2160
+
+// blockGroup holds the events produced by a single block (the block event
+// itself plus its transaction events) while they're waiting to reach the
+// configured confirmation depth, or after they've been released, for
+// potential reorg reporting
+type blockGroup struct {
+	slot     uint64
+	hash     string
+	events   []event.Event
+	txHashes []string
+}
+
+// beginBlockGroup starts buffering a new block's events
+func (c *ChainSync) beginBlockGroup(slot uint64, hash string) {
+	c.currentGroup = &blockGroup{slot: slot, hash: hash}
+	c.pendingBlocks = append(c.pendingBlocks, c.currentGroup)
+}
+
+// bufferGroupEvent appends an event to the block group currently being built.
+// txHash should be empty for the block event itself
+func (c *ChainSync) bufferGroupEvent(evt event.Event, txHash string) {
+	c.currentGroup.events = append(c.currentGroup.events, evt)
+	if txHash != "" {
+		c.currentGroup.txHashes = append(c.currentGroup.txHashes, txHash)
+	}
+}
+
+// releaseConfirmed emits the events for any buffered blocks that have reached
+// the configured confirmation depth relative to tipSlot, oldest first
+func (c *ChainSync) releaseConfirmed(tipSlot uint64) {
+	for len(c.pendingBlocks) > 0 {
+		group := c.pendingBlocks[0]
+		if tipSlot < group.slot || tipSlot-group.slot < uint64(c.confirmations) {
+			break
+		}
+		for _, evt := range group.events {
+			c.eventChan <- evt
+		}
+		// Only checkpoint a block once its events have actually cleared the
+		// confirmation-depth buffer, or a crash between updateStatus and
+		// release would permanently lose them: on restart, Load() would
+		// resume past a slot whose events were never really emitted
+		c.saveCheckpoint(group.slot, group.hash)
+		c.pendingBlocks = c.pendingBlocks[1:]
+		c.releasedBlocks = append(c.releasedBlocks, group)
+	}
+	if len(c.releasedBlocks) > releasedHistoryLimit {
+		c.releasedBlocks = c.releasedBlocks[len(c.releasedBlocks)-releasedHistoryLimit:]
+	}
+}
+
+// handleRollback drops any buffered (not-yet-released) events for blocks
+// after the rollback point and returns the {slot, blockHash, txHash} tuples
+// for any already-released blocks that the rollback invalidates
+func (c *ChainSync) handleRollback(point ocommon.Point) []InvalidatedEvent {
+	keptPending := c.pendingBlocks[:0]
+	for _, group := range c.pendingBlocks {
+		if group.slot <= point.Slot {
+			keptPending = append(keptPending, group)
+		}
+	}
+	c.pendingBlocks = keptPending
+	if c.currentGroup != nil && c.currentGroup.slot > point.Slot {
+		c.currentGroup = nil
+	}
+
+	var invalidated []InvalidatedEvent
+	keptReleased := c.releasedBlocks[:0]
+	for _, group := range c.releasedBlocks {
+		if group.slot <= point.Slot {
+			keptReleased = append(keptReleased, group)
+			continue
+		}
+		invalidated = append(invalidated, InvalidatedEvent{
+			SlotNumber: group.slot,
+			BlockHash:  group.hash,
+		})
+		for _, txHash := range group.txHashes {
+			invalidated = append(invalidated, InvalidatedEvent{
+				SlotNumber:      group.slot,
+				BlockHash:       group.hash,
+				TransactionHash: txHash,
+			})
+		}
+	}
+	c.releasedBlocks = keptReleased
+	return invalidated
+}
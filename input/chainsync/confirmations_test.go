@@ -0,0 +1,203 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"testing"
+	"time"
+
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+
+	"github.com/blinklabs-io/snek/checkpoint"
+	"github.com/blinklabs-io/snek/event"
+)
+
+// fakeCheckpointStore records every Checkpoint passed to Save, in order
+type fakeCheckpointStore struct {
+	saved []checkpoint.Checkpoint
+}
+
+func (f *fakeCheckpointStore) Load() (*checkpoint.Checkpoint, error) { return nil, nil }
+
+func (f *fakeCheckpointStore) Save(cp checkpoint.Checkpoint) error {
+	f.saved = append(f.saved, cp)
+	return nil
+}
+
+func (f *fakeCheckpointStore) Close() error { return nil }
+
+// newTestChainSync returns a ChainSync with just enough state set up to
+// drive the confirmation-depth buffering logic directly, without a real
+// ouroboros connection
+func newTestChainSync(confirmations uint) *ChainSync {
+	return &ChainSync{
+		confirmations: confirmations,
+		eventChan:     make(chan event.Event, 100),
+	}
+}
+
+func drainEvents(c *ChainSync) []event.Event {
+	var evts []event.Event
+	for {
+		select {
+		case evt := <-c.eventChan:
+			evts = append(evts, evt)
+		default:
+			return evts
+		}
+	}
+}
+
+func addBlock(c *ChainSync, slot uint64, hash string) {
+	c.beginBlockGroup(slot, hash)
+	c.bufferGroupEvent(event.New("chainsync.block", time.Time{}, hash), "")
+}
+
+func TestReleaseConfirmedHoldsUntilDepthReached(t *testing.T) {
+	c := newTestChainSync(2)
+	addBlock(c, 100, "a")
+	c.releaseConfirmed(100)
+	if evts := drainEvents(c); len(evts) != 0 {
+		t.Fatalf("releaseConfirmed at depth 0 released %d events, want 0", len(evts))
+	}
+	c.releaseConfirmed(101)
+	if evts := drainEvents(c); len(evts) != 0 {
+		t.Fatalf("releaseConfirmed at depth 1 released %d events, want 0", len(evts))
+	}
+	c.releaseConfirmed(102)
+	if evts := drainEvents(c); len(evts) != 1 {
+		t.Fatalf("releaseConfirmed at depth 2 released %d events, want 1", len(evts))
+	}
+	if len(c.pendingBlocks) != 0 {
+		t.Fatalf("pendingBlocks after release = %d, want 0", len(c.pendingBlocks))
+	}
+	if len(c.releasedBlocks) != 1 {
+		t.Fatalf("releasedBlocks after release = %d, want 1", len(c.releasedBlocks))
+	}
+}
+
+func TestReleaseConfirmedReleasesOldestFirst(t *testing.T) {
+	c := newTestChainSync(1)
+	addBlock(c, 100, "a")
+	addBlock(c, 101, "b")
+	addBlock(c, 102, "c")
+	c.releaseConfirmed(102)
+	evts := drainEvents(c)
+	if len(evts) != 2 {
+		t.Fatalf("releaseConfirmed released %d events, want 2", len(evts))
+	}
+	if evts[0].Payload.(string) != "a" || evts[1].Payload.(string) != "b" {
+		t.Fatalf("released events out of order: %+v", evts)
+	}
+	if len(c.pendingBlocks) != 1 || c.pendingBlocks[0].slot != 102 {
+		t.Fatalf("pendingBlocks after release = %+v, want [slot 102]", c.pendingBlocks)
+	}
+}
+
+func TestHandleRollbackDropsUnreleasedBlocksAfterPoint(t *testing.T) {
+	c := newTestChainSync(10)
+	addBlock(c, 100, "a")
+	addBlock(c, 101, "b")
+	addBlock(c, 102, "c")
+
+	invalidated := c.handleRollback(ocommon.Point{Slot: 101})
+	if len(invalidated) != 0 {
+		t.Fatalf("handleRollback on unreleased blocks reported %d invalidated, want 0 (nothing was released yet)", len(invalidated))
+	}
+	if len(c.pendingBlocks) != 2 {
+		t.Fatalf("pendingBlocks after rollback = %d, want 2", len(c.pendingBlocks))
+	}
+	for _, group := range c.pendingBlocks {
+		if group.slot > 101 {
+			t.Fatalf("pendingBlocks retained a group past the rollback point: %+v", group)
+		}
+	}
+}
+
+func TestHandleRollbackInvalidatesReleasedBlocksAfterPoint(t *testing.T) {
+	c := newTestChainSync(0)
+	addBlock(c, 100, "a")
+	c.releaseConfirmed(100)
+	drainEvents(c)
+	addBlock(c, 101, "b")
+	c.releaseConfirmed(101)
+	drainEvents(c)
+
+	invalidated := c.handleRollback(ocommon.Point{Slot: 100})
+	if len(c.releasedBlocks) != 1 || c.releasedBlocks[0].slot != 100 {
+		t.Fatalf("releasedBlocks after rollback = %+v, want only slot 100", c.releasedBlocks)
+	}
+
+	want := []InvalidatedEvent{
+		{SlotNumber: 101, BlockHash: "b"},
+	}
+	if len(invalidated) != len(want) {
+		t.Fatalf("invalidated = %+v, want %+v", invalidated, want)
+	}
+	for i := range want {
+		if invalidated[i] != want[i] {
+			t.Fatalf("invalidated[%d] = %+v, want %+v", i, invalidated[i], want[i])
+		}
+	}
+}
+
+func TestHandleRollbackInvalidatesReleasedTransactions(t *testing.T) {
+	c := newTestChainSync(0)
+	addBlock(c, 100, "a")
+	c.bufferGroupEvent(event.New("chainsync.transaction", time.Time{}, "tx-a"), "tx-a")
+	c.releaseConfirmed(100)
+	drainEvents(c)
+
+	invalidated := c.handleRollback(ocommon.Point{Slot: 99})
+	want := []InvalidatedEvent{
+		{SlotNumber: 100, BlockHash: "a"},
+		{SlotNumber: 100, BlockHash: "a", TransactionHash: "tx-a"},
+	}
+	if len(invalidated) != len(want) {
+		t.Fatalf("invalidated = %+v, want %+v", invalidated, want)
+	}
+	for i := range want {
+		if invalidated[i] != want[i] {
+			t.Fatalf("invalidated[%d] = %+v, want %+v", i, invalidated[i], want[i])
+		}
+	}
+}
+
+func TestReleaseConfirmedSavesCheckpointOnlyOnRelease(t *testing.T) {
+	store := &fakeCheckpointStore{}
+	c := newTestChainSync(1)
+	c.checkpointStore = store
+	addBlock(c, 100, "a")
+	addBlock(c, 101, "b")
+	// Neither block has reached the confirmation depth yet, so a crash here
+	// must not have already advanced the checkpoint past them
+	if len(store.saved) != 0 {
+		t.Fatalf("checkpoint saved before any block was released: %+v", store.saved)
+	}
+	c.releaseConfirmed(101)
+	drainEvents(c)
+	if len(store.saved) != 1 || store.saved[0] != (checkpoint.Checkpoint{Slot: 100, Hash: "a"}) {
+		t.Fatalf("checkpoint saved = %+v, want a single save for slot 100", store.saved)
+	}
+}
+
+func TestHandleRollbackClearsInProgressGroupPastPoint(t *testing.T) {
+	c := newTestChainSync(10)
+	addBlock(c, 100, "a")
+	c.handleRollback(ocommon.Point{Slot: 99})
+	if c.currentGroup != nil {
+		t.Fatalf("currentGroup after rollback past its slot = %+v, want nil", c.currentGroup)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+// InvalidatedEvent identifies a single block or transaction that was already
+// emitted on OutputChan() but has since been rolled back. TransactionHash is
+// empty for the tuple representing the block itself
+type InvalidatedEvent struct {
+	SlotNumber      uint64 `json:"slotNumber"`
+	BlockHash       string `json:"blockHash"`
+	TransactionHash string `json:"transactionHash,omitempty"`
+}
+
+// ReorgEvent carries the blocks/transactions invalidated by a rollback that
+// occurred after their events had already been released downstream
+type ReorgEvent struct {
+	Invalidated []InvalidatedEvent `json:"invalidated"`
+}
+
+func NewReorgEvent(invalidated []InvalidatedEvent) ReorgEvent {
+	return ReorgEvent{
+		Invalidated: invalidated,
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger"
+
+	"github.com/blinklabs-io/snek/event"
+)
+
+// RedeemerEvent describes a single Plutus redeemer attached to a transaction
+type RedeemerEvent struct {
+	Tag     string      `json:"tag"`
+	Index   uint32      `json:"index"`
+	Data    *cbor.Value `json:"data,omitempty"`
+	ExUnits ExUnits     `json:"exUnits"`
+}
+
+// ExUnits is the Plutus execution budget consumed by a redeemer
+type ExUnits struct {
+	Memory uint64 `json:"memory"`
+	Steps  uint64 `json:"steps"`
+}
+
+// ScriptEvent describes a reference script attached to one of a
+// transaction's outputs. Scripts supplied in the witness set are not
+// included
+type ScriptEvent struct {
+	Hash string           `json:"hash"`
+	Type string           `json:"type"`
+	Cbor byteSliceJsonHex `json:"cbor,omitempty"`
+}
+
+// CertificateEvent describes a single certificate included in a transaction
+// (stake registration/delegation, pool registration/retirement, etc)
+type CertificateEvent struct {
+	Type string           `json:"type"`
+	Cbor byteSliceJsonHex `json:"cbor,omitempty"`
+}
+
+func newRedeemerEvents(tx ledger.Transaction) []RedeemerEvent {
+	redeemers := tx.Redeemers()
+	if redeemers == nil {
+		return nil
+	}
+	ret := make([]RedeemerEvent, 0, len(redeemers))
+	for _, r := range redeemers {
+		ret = append(ret, RedeemerEvent{
+			Tag:   r.Tag(),
+			Index: r.Index(),
+			Data:  r.Data(),
+			ExUnits: ExUnits{
+				Memory: r.ExUnits().Memory,
+				Steps:  r.ExUnits().Steps,
+			},
+		})
+	}
+	return ret
+}
+
+func newReferenceScriptEvents(tx ledger.Transaction) []ScriptEvent {
+	var ret []ScriptEvent
+	for _, output := range tx.Outputs() {
+		script := output.ReferenceScript()
+		if script == nil {
+			continue
+		}
+		ret = append(ret, ScriptEvent{
+			Hash: script.Hash(),
+			Type: script.Type(),
+			Cbor: script.Cbor(),
+		})
+	}
+	return ret
+}
+
+func newCertificateEvents(tx ledger.Transaction) []CertificateEvent {
+	certs := tx.Certificates()
+	if certs == nil {
+		return nil
+	}
+	ret := make([]CertificateEvent, 0, len(certs))
+	for _, cert := range certs {
+		ret = append(ret, CertificateEvent{
+			Type: cert.Type(),
+			Cbor: cert.Cbor(),
+		})
+	}
+	return ret
+}
+
+// ScriptInvocationEvent is emitted once per redeemer in a transaction, so
+// consumers that only care about Plutus activity don't have to parse every
+// transaction to find the few that invoke a script
+type ScriptInvocationEvent struct {
+	BlockNumber     uint64        `json:"blockNumber"`
+	BlockHash       string        `json:"blockHash"`
+	SlotNumber      uint64        `json:"slotNumber"`
+	TransactionHash string        `json:"transactionHash"`
+	Redeemer        RedeemerEvent `json:"redeemer"`
+}
+
+// newScriptInvocationEvents builds one chainsync.script_invocation event per
+// redeemer carried by a transaction event
+func newScriptInvocationEvents(txEvt TransactionEvent) []event.Event {
+	if len(txEvt.Redeemers) == 0 {
+		return nil
+	}
+	now := time.Now()
+	ret := make([]event.Event, len(txEvt.Redeemers))
+	for i, redeemer := range txEvt.Redeemers {
+		ret[i] = event.New(
+			"chainsync.script_invocation",
+			now,
+			ScriptInvocationEvent{
+				BlockNumber:     txEvt.BlockNumber,
+				BlockHash:       txEvt.BlockHash,
+				SlotNumber:      txEvt.SlotNumber,
+				TransactionHash: txEvt.TransactionHash,
+				Redeemer:        redeemer,
+			},
+		)
+	}
+	return ret
+}
+
+func newDatumsByHash(tx ledger.Transaction) map[string]*cbor.Value {
+	datums := tx.Datums()
+	if len(datums) == 0 {
+		return nil
+	}
+	ret := make(map[string]*cbor.Value, len(datums))
+	for hash, datum := range datums {
+		ret[hash] = datum
+	}
+	return ret
+}
@@ -20,24 +20,32 @@ import (
 )
 
 type TransactionEvent struct {
-	BlockNumber     uint64                     `json:"blockNumber"`
-	BlockHash       string                     `json:"blockHash"`
-	SlotNumber      uint64                     `json:"slotNumber"`
-	TransactionHash string                     `json:"transactionHash"`
-	TransactionCbor byteSliceJsonHex           `json:"transactionCbor,omitempty"`
-	Inputs          []ledger.TransactionInput  `json:"inputs"`
-	Outputs         []ledger.TransactionOutput `json:"outputs"`
-	Metadata        *cbor.Value                `json:"metadata,omitempty"`
+	BlockNumber      uint64                     `json:"blockNumber"`
+	BlockHash        string                     `json:"blockHash"`
+	SlotNumber       uint64                     `json:"slotNumber"`
+	TransactionHash  string                     `json:"transactionHash"`
+	TransactionCbor  byteSliceJsonHex           `json:"transactionCbor,omitempty"`
+	Inputs           []ledger.TransactionInput  `json:"inputs"`
+	Outputs          []ledger.TransactionOutput `json:"outputs"`
+	Metadata         *cbor.Value                `json:"metadata,omitempty"`
+	Datums           map[string]*cbor.Value     `json:"datums,omitempty"`
+	Redeemers        []RedeemerEvent            `json:"redeemers,omitempty"`
+	ReferenceScripts []ScriptEvent              `json:"referenceScripts,omitempty"`
+	Certificates     []CertificateEvent         `json:"certificates,omitempty"`
 }
 
 func NewTransactionEvent(block ledger.Block, tx ledger.Transaction, includeCbor bool) TransactionEvent {
 	evt := TransactionEvent{
-		BlockNumber:     block.BlockNumber(),
-		BlockHash:       block.Hash(),
-		SlotNumber:      block.SlotNumber(),
-		TransactionHash: tx.Hash(),
-		Inputs:          tx.Inputs(),
-		Outputs:         tx.Outputs(),
+		BlockNumber:      block.BlockNumber(),
+		BlockHash:        block.Hash(),
+		SlotNumber:       block.SlotNumber(),
+		TransactionHash:  tx.Hash(),
+		Inputs:           tx.Inputs(),
+		Outputs:          tx.Outputs(),
+		Datums:           newDatumsByHash(tx),
+		Redeemers:        newRedeemerEvents(tx),
+		ReferenceScripts: newReferenceScriptEvents(tx),
+		Certificates:     newCertificateEvents(tx),
 	}
 	if includeCbor {
 		evt.TransactionCbor = tx.Cbor()
@@ -0,0 +1,29 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/blinklabs-io/snek/event"
+)
+
+// ObserveEvent updates event-type-specific counters (currently just the
+// rollback counter) for an event read off a pipeline stage's OutputChan().
+// It's safe to call for every event type; unrecognized types are a no-op
+func ObserveEvent(evt event.Event) {
+	switch evt.Type {
+	case "chainsync.rollback":
+		RollbacksTotal.Inc()
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics provides the Prometheus metrics and health/readiness
+// endpoints for a snek pipeline. Stages report into it via the package-level
+// metric vars below rather than through an injected interface, matching how
+// the standard Prometheus client libraries are normally used; input/chainsync
+// stays decoupled by feeding metrics through its existing WithStatusUpdateFunc
+// hook (see NewStatusUpdateFunc in this package) instead of importing it directly
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	TipLagSlots = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "snek",
+		Subsystem: "chainsync",
+		Name:      "tip_lag_slots",
+		Help:      "Number of slots between the last processed block and the chain tip",
+	})
+
+	BlocksProcessedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "snek",
+		Subsystem: "chainsync",
+		Name:      "blocks_processed_total",
+		Help:      "Total number of blocks processed",
+	})
+
+	RollbacksTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "snek",
+		Subsystem: "chainsync",
+		Name:      "rollbacks_total",
+		Help:      "Total number of rollback events received",
+	})
+
+	BulkRangeProgress = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "snek",
+		Subsystem: "chainsync",
+		Name:      "bulk_range_progress_ratio",
+		Help:      "Fraction of the current bulk sync range that has been processed, from 0 to 1",
+	})
+
+	FilterEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "snek",
+		Subsystem: "filter",
+		Name:      "events_total",
+		Help:      "Total number of events seen by the filter stage, by dimension and result",
+	}, []string{"dimension", "result"})
+
+	OutputSendTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "snek",
+		Subsystem: "output",
+		Name:      "send_total",
+		Help:      "Total number of output batches sent, by plugin and result",
+	}, []string{"plugin", "result"})
+
+	OutputRetryDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "snek",
+		Subsystem: "output",
+		Name:      "retry_depth",
+		Help:      "Number of retry attempts made for the batch currently in flight, by plugin",
+	}, []string{"plugin"})
+
+	OutputSendLatencySeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "snek",
+		Subsystem: "output",
+		Name:      "send_latency_seconds",
+		Help:      "Latency of a successful output batch send, by plugin",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"plugin"})
+)
+
+// Filter event result labels used with FilterEventsTotal
+const (
+	FilterResultPassed  = "passed"
+	FilterResultDropped = "dropped"
+)
+
+// Output send result labels used with OutputSendTotal
+const (
+	OutputResultSuccess = "success"
+	OutputResultFailure = "failure"
+)
@@ -0,0 +1,115 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server embeds a single HTTP server exposing /metrics (Prometheus text
+// format), /healthz (always 200 once the server has started), and /readyz
+// (200 once the chain sync tip has been reached, or once the slot lag drops
+// within the configured threshold)
+type Server struct {
+	listenAddr       string
+	slotLagThreshold uint64
+	httpServer       *http.Server
+	errorChan        chan error
+
+	mutex      sync.RWMutex
+	tipReached bool
+	slotLag    uint64
+}
+
+// NewServer returns a Server listening on listenAddr. slotLagThreshold makes
+// /readyz return 200 once the tip-slot lag drops to or below it, in addition
+// to the usual "tip reached" condition; a value of 0 requires the tip to
+// have actually been reached
+func NewServer(listenAddr string, slotLagThreshold uint64) *Server {
+	return &Server{
+		listenAddr:       listenAddr,
+		slotLagThreshold: slotLagThreshold,
+		// No status has been reported yet, so the lag is unknown
+		slotLag:   ^uint64(0),
+		errorChan: make(chan error, 1),
+	}
+}
+
+// Start binds the listener and begins serving /metrics, /healthz, and
+// /readyz. The listener is bound synchronously, so a failure (e.g. the port
+// already being in use) is returned from Start instead of being dropped by
+// a goroutine that races the caller; any error Serve returns afterward is
+// sent to ErrorChan instead
+func (s *Server) Start() error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if s.ready() {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	ln, err := net.Listen("tcp", s.listenAddr)
+	if err != nil {
+		return err
+	}
+	s.httpServer = &http.Server{Handler: mux}
+	go func() {
+		if err := s.httpServer.Serve(ln); err != nil && err != http.ErrServerClosed {
+			select {
+			case s.errorChan <- err:
+			default:
+			}
+		}
+	}()
+	return nil
+}
+
+// ErrorChan returns a channel that receives at most one error from the
+// underlying HTTP server after it has successfully started, e.g. if the
+// listener is closed unexpectedly
+func (s *Server) ErrorChan() <-chan error {
+	return s.errorChan
+}
+
+// Stop shuts down the HTTP server
+func (s *Server) Stop() error {
+	if s.httpServer == nil {
+		return nil
+	}
+	return s.httpServer.Shutdown(context.Background())
+}
+
+func (s *Server) setStatus(tipReached bool, slotLag uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tipReached = tipReached
+	s.slotLag = slotLag
+}
+
+func (s *Server) ready() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.tipReached || (s.slotLagThreshold > 0 && s.slotLag <= s.slotLagThreshold)
+}
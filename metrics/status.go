@@ -0,0 +1,44 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/blinklabs-io/snek/input/chainsync"
+)
+
+// NewStatusUpdateFunc returns a function suitable for
+// chainsync.WithStatusUpdateFunc that updates the tip-lag gauge and the
+// given Server's readiness state as the chain sync input makes progress
+func NewStatusUpdateFunc(server *Server) chainsync.StatusUpdateFunc {
+	return func(status chainsync.ChainSyncStatus) {
+		BlocksProcessedTotal.Inc()
+		var lag uint64
+		if status.TipSlotNumber > status.SlotNumber {
+			lag = status.TipSlotNumber - status.SlotNumber
+		}
+		TipLagSlots.Set(float64(lag))
+		if status.BulkRangeEndSlot > status.BulkRangeStartSlot {
+			total := float64(status.BulkRangeEndSlot - status.BulkRangeStartSlot)
+			var done float64
+			if status.SlotNumber > status.BulkRangeStartSlot {
+				done = float64(status.SlotNumber - status.BulkRangeStartSlot)
+			}
+			BulkRangeProgress.Set(done / total)
+		}
+		if server != nil {
+			server.setStatus(status.TipReached, lag)
+		}
+	}
+}
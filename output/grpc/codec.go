@@ -0,0 +1,55 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// rawCodecName is registered as a gRPC codec that passes a pre-serialized
+// event batch through as raw bytes. This lets the gRPC sink push events to
+// any server willing to accept a byte-for-byte JSON or CBOR payload (per
+// WithCbor) without requiring consumers to vendor a generated protobuf
+// client for snek's event types.
+const rawCodecName = "raw"
+
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("grpc: rawCodec expected []byte, got %T", v)
+	}
+	return b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("grpc: rawCodec expected *[]byte, got %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return rawCodecName
+}
+
+func init() {
+	encoding.RegisterCodec(rawCodec{})
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpc implements a generic gRPC push output plugin. Rather than
+// requiring downstream consumers to vendor a generated protobuf client,
+// events are pushed as a pre-serialized (JSON or CBOR) batch via the
+// /snek.EventSink/Push method using the "raw" codec registered in codec.go
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/blinklabs-io/snek/event"
+	"github.com/blinklabs-io/snek/output/internal/sink"
+	"github.com/blinklabs-io/snek/pipeline"
+)
+
+const pushMethod = "/snek.EventSink/Push"
+
+type Grpc struct {
+	errorChan    chan error
+	inputChan    chan event.Event
+	seqInputChan chan pipeline.SequencedEvent
+	addr         string
+	useCbor      bool
+	processor    *sink.Processor
+	sinkOptions  []sink.ProcessorOptionFunc
+	conn         *ggrpc.ClientConn
+	ackFunc      pipeline.AckFunc
+}
+
+// New returns a new Grpc object with the specified options applied
+func New(options ...GrpcOptionFunc) *Grpc {
+	g := &Grpc{
+		errorChan:    make(chan error),
+		inputChan:    make(chan event.Event, 10),
+		seqInputChan: make(chan pipeline.SequencedEvent, 10),
+	}
+	for _, option := range options {
+		option(g)
+	}
+	return g
+}
+
+// Start the gRPC output
+func (g *Grpc) Start() error {
+	conn, err := ggrpc.NewClient(
+		g.addr,
+		ggrpc.WithTransportCredentials(insecure.NewCredentials()),
+		ggrpc.WithDefaultCallOptions(ggrpc.CallContentSubtype(rawCodecName)),
+	)
+	if err != nil {
+		return err
+	}
+	g.conn = conn
+	options := append([]sink.ProcessorOptionFunc{sink.WithPluginName("grpc")}, g.sinkOptions...)
+	if g.ackFunc != nil {
+		options = append(options, sink.WithAckFunc(func(seq uint64) { g.ackFunc(seq) }))
+	}
+	g.processor = sink.NewProcessor(g.send, options...)
+	if err := g.processor.Start(); err != nil {
+		return err
+	}
+	// Both forwarding goroutines must drain their channel before the
+	// processor is stopped, or a send on its already-closed SeqInputChan
+	// (or InputChan) can race Stop() and panic
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for evt := range g.inputChan {
+			g.processor.InputChan() <- evt
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for item := range g.seqInputChan {
+			g.processor.SeqInputChan() <- sink.SeqItem{Event: item.Event, Seq: item.Seq}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		_ = g.processor.Stop()
+	}()
+	return nil
+}
+
+// Stop the gRPC output
+func (g *Grpc) Stop() error {
+	close(g.inputChan)
+	close(g.seqInputChan)
+	close(g.errorChan)
+	return g.conn.Close()
+}
+
+// SeqInputChan returns the channel used to feed WAL-sequenced events into
+// the gRPC output, for use by a pipeline.Pipeline configured with a WAL
+func (g *Grpc) SeqInputChan() chan<- pipeline.SequencedEvent {
+	return g.seqInputChan
+}
+
+// SetAckFunc registers the callback invoked with the highest WAL sequence
+// number this output has durably delivered so far
+func (g *Grpc) SetAckFunc(fn pipeline.AckFunc) {
+	g.ackFunc = fn
+}
+
+// ErrorChan returns the output error channel
+func (g *Grpc) ErrorChan() chan error {
+	return g.errorChan
+}
+
+// InputChan returns the input event channel
+func (g *Grpc) InputChan() chan<- event.Event {
+	return g.inputChan
+}
+
+// OutputChan always returns nil
+func (g *Grpc) OutputChan() <-chan event.Event {
+	return nil
+}
+
+// pushBatch is the wire payload sent to the server: the serialized events
+// plus their partition-key hints, so servers that care about ordering (e.g.
+// forwarding on to Kafka) don't have to re-derive them
+type pushBatch struct {
+	Keys   []string          `json:"keys" cbor:"keys"`
+	Events []json.RawMessage `json:"events" cbor:"events"`
+}
+
+func (g *Grpc) send(batch []event.Event) error {
+	payload := pushBatch{
+		Keys:   make([]string, len(batch)),
+		Events: make([]json.RawMessage, len(batch)),
+	}
+	for i, evt := range batch {
+		data, err := sink.Marshal(evt, g.useCbor)
+		if err != nil {
+			return err
+		}
+		payload.Keys[i] = sink.PartitionKey(evt)
+		payload.Events[i] = data
+	}
+	var data []byte
+	var err error
+	if g.useCbor {
+		data, err = cbor.Marshal(payload)
+	} else {
+		data, err = json.Marshal(payload)
+	}
+	if err != nil {
+		return err
+	}
+	var reply []byte
+	return g.conn.Invoke(context.Background(), pushMethod, data, &reply)
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"time"
+
+	"github.com/blinklabs-io/snek/output/internal/sink"
+)
+
+type GrpcOptionFunc func(*Grpc)
+
+// WithAddr specifies the host:port of the gRPC server to push events to
+func WithAddr(addr string) GrpcOptionFunc {
+	return func(g *Grpc) {
+		g.addr = addr
+	}
+}
+
+// WithCbor enables CBOR event serialization instead of the default JSON
+func WithCbor(useCbor bool) GrpcOptionFunc {
+	return func(g *Grpc) {
+		g.useCbor = useCbor
+	}
+}
+
+// WithBatchSize sets the number of events to accumulate before pushing a batch
+func WithBatchSize(batchSize int) GrpcOptionFunc {
+	return func(g *Grpc) {
+		g.sinkOptions = append(g.sinkOptions, sink.WithBatchSize(batchSize))
+	}
+}
+
+// WithBatchTimeout sets the max time to wait before pushing a partial batch
+func WithBatchTimeout(timeout time.Duration) GrpcOptionFunc {
+	return func(g *Grpc) {
+		g.sinkOptions = append(g.sinkOptions, sink.WithBatchTimeout(timeout))
+	}
+}
+
+// WithConcurrency sets the number of batches that may be in flight at once
+func WithConcurrency(concurrency int) GrpcOptionFunc {
+	return func(g *Grpc) {
+		g.sinkOptions = append(g.sinkOptions, sink.WithConcurrency(concurrency))
+	}
+}
+
+// WithMaxRetries sets the number of retry attempts before a batch is dead-lettered
+func WithMaxRetries(maxRetries int) GrpcOptionFunc {
+	return func(g *Grpc) {
+		g.sinkOptions = append(g.sinkOptions, sink.WithMaxRetries(maxRetries))
+	}
+}
+
+// WithDeadLetterPath sets the file that failed batches are appended to
+func WithDeadLetterPath(path string) GrpcOptionFunc {
+	return func(g *Grpc) {
+		g.sinkOptions = append(g.sinkOptions, sink.WithDeadLetterPath(path))
+	}
+}
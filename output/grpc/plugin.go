@@ -0,0 +1,59 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"github.com/blinklabs-io/snek/plugin"
+)
+
+var cmdlineOptions struct {
+	addr    string
+	useCbor bool
+}
+
+func init() {
+	plugin.Register(
+		plugin.PluginEntry{
+			Type:               plugin.PluginTypeOutput,
+			Name:               "grpc",
+			Description:        "send events to a generic gRPC event sink",
+			NewFromOptionsFunc: NewFromCmdlineOptions,
+			Options: []plugin.PluginOption{
+				{
+					Name:         "addr",
+					Type:         plugin.PluginOptionTypeString,
+					Description:  "specifies the host:port of the gRPC server to push events to",
+					DefaultValue: "localhost:50051",
+					Dest:         &(cmdlineOptions.addr),
+				},
+				{
+					Name:         "cbor",
+					Type:         plugin.PluginOptionTypeBool,
+					Description:  "serialize events as CBOR instead of JSON",
+					DefaultValue: false,
+					Dest:         &(cmdlineOptions.useCbor),
+				},
+			},
+		},
+	)
+}
+
+func NewFromCmdlineOptions() plugin.Plugin {
+	p := New(
+		WithAddr(cmdlineOptions.addr),
+		WithCbor(cmdlineOptions.useCbor),
+	)
+	return p
+}
@@ -0,0 +1,276 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sink provides cross-cutting behavior shared by the streaming
+// output plugins (Kafka, NATS JetStream, Redis Streams, gRPC, ...): batching,
+// retry with backoff, bounded concurrency, and dead-letter handling for
+// events that never make it to the destination.
+package sink
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/blinklabs-io/snek/event"
+	"github.com/blinklabs-io/snek/input/chainsync"
+	"github.com/blinklabs-io/snek/metrics"
+)
+
+// SendFunc delivers a batch of events to the destination. It should return
+// a non-nil error unless every event in the batch was durably accepted;
+// Processor does not support partial-batch failure and retries the whole
+// batch, so events that were already accepted may be delivered again.
+type SendFunc func(batch []event.Event) error
+
+// SeqItem pairs an event with the WAL sequence number a pipeline.Pipeline
+// assigned it, for delivery via Processor's SeqInputChan.
+type SeqItem struct {
+	Event event.Event
+	Seq   uint64
+}
+
+// Processor batches incoming events and hands them to a SendFunc, retrying
+// on failure with exponential backoff and jitter before giving up and
+// writing the batch to the configured dead-letter sink.
+type Processor struct {
+	sendFunc       SendFunc
+	pluginName     string
+	batchSize      int
+	batchTimeout   time.Duration
+	concurrency    int
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	deadLetterPath string
+	ackFunc        func(seq uint64)
+
+	inputChan    chan event.Event
+	seqInputChan chan SeqItem
+	batchSem     chan struct{}
+	doneChan     chan struct{}
+	wg           sync.WaitGroup
+
+	deadLetterMutex sync.Mutex
+	deadLetterFile  *os.File
+}
+
+// NewProcessor returns a Processor with the given SendFunc and options
+// applied. If an ack func is configured (via WithAckFunc), concurrency is
+// forced to 1 regardless of WithConcurrency: ackFunc is called with the
+// highest seq in a completed batch, which is only safe to treat as "every
+// seq up to here is durably sent" if batches are sent strictly in the order
+// they were appended to the WAL, and concurrent in-flight batches can
+// complete out of order
+func NewProcessor(sendFunc SendFunc, options ...ProcessorOptionFunc) *Processor {
+	p := &Processor{
+		sendFunc:       sendFunc,
+		batchSize:      100,
+		batchTimeout:   5 * time.Second,
+		concurrency:    1,
+		maxRetries:     5,
+		initialBackoff: 100 * time.Millisecond,
+		maxBackoff:     30 * time.Second,
+		inputChan:      make(chan event.Event, 100),
+		seqInputChan:   make(chan SeqItem, 100),
+		doneChan:       make(chan struct{}),
+	}
+	for _, option := range options {
+		option(p)
+	}
+	if p.ackFunc != nil {
+		p.concurrency = 1
+	}
+	p.batchSem = make(chan struct{}, p.concurrency)
+	return p
+}
+
+// Start begins batching events from the input channel and dispatching them
+// to the configured SendFunc
+func (p *Processor) Start() error {
+	if p.deadLetterPath != "" {
+		f, err := os.OpenFile(p.deadLetterPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		p.deadLetterFile = f
+	}
+	p.wg.Add(1)
+	go p.batchLoop()
+	return nil
+}
+
+// Stop flushes any pending events and shuts down the processor
+func (p *Processor) Stop() error {
+	close(p.inputChan)
+	close(p.seqInputChan)
+	p.wg.Wait()
+	close(p.doneChan)
+	if p.deadLetterFile != nil {
+		return p.deadLetterFile.Close()
+	}
+	return nil
+}
+
+// InputChan returns the channel used to feed events into the processor
+func (p *Processor) InputChan() chan<- event.Event {
+	return p.inputChan
+}
+
+// SeqInputChan returns the channel used to feed WAL-sequenced events into
+// the processor. Use this in place of InputChan when the processor was
+// configured with WithAckFunc, so completed batches can be acknowledged
+func (p *Processor) SeqInputChan() chan<- SeqItem {
+	return p.seqInputChan
+}
+
+func (p *Processor) batchLoop() {
+	defer p.wg.Done()
+	batch := make([]event.Event, 0, p.batchSize)
+	seqs := make([]uint64, 0, p.batchSize)
+	timer := time.NewTimer(p.batchTimeout)
+	defer timer.Stop()
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		toSend, toSeqs := batch, seqs
+		batch = make([]event.Event, 0, p.batchSize)
+		seqs = make([]uint64, 0, p.batchSize)
+		p.wg.Add(1)
+		p.batchSem <- struct{}{}
+		go func() {
+			defer p.wg.Done()
+			defer func() { <-p.batchSem }()
+			p.sendWithRetry(toSend, toSeqs)
+		}()
+	}
+	inputChan, seqInputChan := p.inputChan, p.seqInputChan
+	for inputChan != nil || seqInputChan != nil {
+		select {
+		case evt, ok := <-inputChan:
+			if !ok {
+				inputChan = nil
+				continue
+			}
+			batch = append(batch, evt)
+			seqs = append(seqs, 0)
+		case item, ok := <-seqInputChan:
+			if !ok {
+				seqInputChan = nil
+				continue
+			}
+			batch = append(batch, item.Event)
+			seqs = append(seqs, item.Seq)
+		case <-timer.C:
+			flush()
+			timer.Reset(p.batchTimeout)
+			continue
+		}
+		if len(batch) >= p.batchSize {
+			flush()
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(p.batchTimeout)
+		}
+	}
+	flush()
+}
+
+// sendWithRetry sends batch, retrying with backoff on failure. seqs holds
+// the WAL sequence number assigned to each event in batch, or 0 where none
+// applies; on success, ackFunc (if set) is called with the highest seq in
+// the batch. NewProcessor forces concurrency to 1 whenever ackFunc is set,
+// so batches are always sent (and therefore acked) in the same order they
+// were appended to the WAL. A batch that exhausts its retries is
+// dead-lettered instead and its seqs are not acknowledged; they're replayed
+// from the WAL on restart only until a later batch succeeds, since that
+// batch's ack covers every seq up to its own and lets the WAL commit past
+// them. From then on the dead-letter file (if configured) is the only copy
+func (p *Processor) sendWithRetry(batch []event.Event, seqs []uint64) {
+	backoff := p.initialBackoff
+	for attempt := 0; attempt <= p.maxRetries; attempt++ {
+		metrics.OutputRetryDepth.WithLabelValues(p.pluginName).Set(float64(attempt))
+		start := time.Now()
+		err := p.sendFunc(batch)
+		if err == nil {
+			metrics.OutputSendTotal.WithLabelValues(p.pluginName, metrics.OutputResultSuccess).Inc()
+			metrics.OutputSendLatencySeconds.WithLabelValues(p.pluginName).Observe(time.Since(start).Seconds())
+			metrics.OutputRetryDepth.WithLabelValues(p.pluginName).Set(0)
+			if p.ackFunc != nil {
+				var maxSeq uint64
+				for _, seq := range seqs {
+					if seq > maxSeq {
+						maxSeq = seq
+					}
+				}
+				if maxSeq > 0 {
+					p.ackFunc(maxSeq)
+				}
+			}
+			return
+		}
+		metrics.OutputSendTotal.WithLabelValues(p.pluginName, metrics.OutputResultFailure).Inc()
+		if attempt == p.maxRetries {
+			break
+		}
+		// Full jitter: sleep somewhere between 0 and the current backoff ceiling
+		sleep := time.Duration(rand.Int63n(int64(backoff)))
+		time.Sleep(sleep)
+		backoff *= 2
+		if backoff > p.maxBackoff {
+			backoff = p.maxBackoff
+		}
+	}
+	p.deadLetter(batch)
+}
+
+func (p *Processor) deadLetter(batch []event.Event) {
+	if p.deadLetterFile == nil {
+		return
+	}
+	p.deadLetterMutex.Lock()
+	defer p.deadLetterMutex.Unlock()
+	enc := json.NewEncoder(p.deadLetterFile)
+	for _, evt := range batch {
+		_ = enc.Encode(evt)
+	}
+}
+
+// Marshal serializes an event as JSON, or as CBOR when useCbor is true
+func Marshal(evt event.Event, useCbor bool) ([]byte, error) {
+	if useCbor {
+		return cbor.Marshal(evt)
+	}
+	return json.Marshal(evt)
+}
+
+// PartitionKey derives a stable partition-key hint for an event's payload so
+// that downstream partitioned transports (e.g. Kafka) keep all events for the
+// same transaction or block on the same partition
+func PartitionKey(evt event.Event) string {
+	switch v := evt.Payload.(type) {
+	case chainsync.TransactionEvent:
+		return v.TransactionHash
+	case chainsync.BlockEvent:
+		return v.BlockHash
+	default:
+		return ""
+	}
+}
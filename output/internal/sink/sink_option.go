@@ -0,0 +1,89 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import "time"
+
+type ProcessorOptionFunc func(*Processor)
+
+// WithPluginName sets the plugin name used as the "plugin" label on the
+// output metrics this Processor records
+func WithPluginName(name string) ProcessorOptionFunc {
+	return func(p *Processor) {
+		p.pluginName = name
+	}
+}
+
+// WithBatchSize sets the number of events to accumulate before sending a batch
+func WithBatchSize(batchSize int) ProcessorOptionFunc {
+	return func(p *Processor) {
+		p.batchSize = batchSize
+	}
+}
+
+// WithBatchTimeout sets the max time to wait before sending a partial batch
+func WithBatchTimeout(timeout time.Duration) ProcessorOptionFunc {
+	return func(p *Processor) {
+		p.batchTimeout = timeout
+	}
+}
+
+// WithConcurrency sets the number of batches that may be in flight at once.
+// Ignored (forced to 1) if the Processor is also configured with WithAckFunc,
+// since acking requires batches to complete in the order they were sent
+func WithConcurrency(concurrency int) ProcessorOptionFunc {
+	return func(p *Processor) {
+		p.concurrency = concurrency
+	}
+}
+
+// WithMaxRetries sets the number of retry attempts before a batch is dead-lettered
+func WithMaxRetries(maxRetries int) ProcessorOptionFunc {
+	return func(p *Processor) {
+		p.maxRetries = maxRetries
+	}
+}
+
+// WithInitialBackoff sets the starting delay for the retry backoff
+func WithInitialBackoff(backoff time.Duration) ProcessorOptionFunc {
+	return func(p *Processor) {
+		p.initialBackoff = backoff
+	}
+}
+
+// WithMaxBackoff sets the ceiling for the retry backoff
+func WithMaxBackoff(backoff time.Duration) ProcessorOptionFunc {
+	return func(p *Processor) {
+		p.maxBackoff = backoff
+	}
+}
+
+// WithDeadLetterPath sets the file path that batches are appended to as
+// newline-delimited JSON once they exceed the configured retry count. An
+// empty path (the default) disables dead-lettering
+func WithDeadLetterPath(path string) ProcessorOptionFunc {
+	return func(p *Processor) {
+		p.deadLetterPath = path
+	}
+}
+
+// WithAckFunc sets the callback invoked, after a batch fed in via
+// SeqInputChan is successfully sent, with the highest WAL sequence number
+// in that batch
+func WithAckFunc(fn func(seq uint64)) ProcessorOptionFunc {
+	return func(p *Processor) {
+		p.ackFunc = fn
+	}
+}
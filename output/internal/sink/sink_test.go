@@ -0,0 +1,100 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/snek/event"
+)
+
+func TestNewProcessorForcesConcurrencyToOneWithAckFunc(t *testing.T) {
+	p := NewProcessor(
+		func(batch []event.Event) error { return nil },
+		WithConcurrency(8),
+		WithAckFunc(func(seq uint64) {}),
+	)
+	if p.concurrency != 1 {
+		t.Fatalf("concurrency = %d, want 1 when an ack func is configured", p.concurrency)
+	}
+	if cap(p.batchSem) != 1 {
+		t.Fatalf("batchSem capacity = %d, want 1", cap(p.batchSem))
+	}
+}
+
+func TestNewProcessorKeepsConcurrencyWithoutAckFunc(t *testing.T) {
+	p := NewProcessor(
+		func(batch []event.Event) error { return nil },
+		WithConcurrency(8),
+	)
+	if p.concurrency != 8 {
+		t.Fatalf("concurrency = %d, want 8 when no ack func is configured", p.concurrency)
+	}
+}
+
+// TestProcessorAcksInSubmissionOrderUnderConcurrency feeds several
+// single-event batches through a Processor configured with both
+// WithConcurrency and WithAckFunc, where earlier batches are made to take
+// longer to send than later ones. If concurrency weren't forced to 1, the
+// later (faster) batches could complete and ack before the earlier ones,
+// advancing a WAL commit cursor past events that aren't durably sent yet.
+func TestProcessorAcksInSubmissionOrderUnderConcurrency(t *testing.T) {
+	const n = 5
+	var sendMutex sync.Mutex
+	sendOrder := make([]uint64, 0, n)
+
+	var ackMutex sync.Mutex
+	var ackedSeqs []uint64
+
+	p := NewProcessor(
+		func(batch []event.Event) error {
+			// Earlier batches (lower seq) sleep longer, so a concurrent
+			// implementation would tend to finish later batches first
+			sendMutex.Lock()
+			sendOrder = append(sendOrder, 0)
+			sendMutex.Unlock()
+			time.Sleep(time.Duration(n-len(sendOrder)) * 5 * time.Millisecond)
+			return nil
+		},
+		WithBatchSize(1),
+		WithBatchTimeout(time.Millisecond),
+		WithConcurrency(n),
+		WithAckFunc(func(seq uint64) {
+			ackMutex.Lock()
+			ackedSeqs = append(ackedSeqs, seq)
+			ackMutex.Unlock()
+		}),
+	)
+	if err := p.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	for seq := uint64(1); seq <= n; seq++ {
+		p.SeqInputChan() <- SeqItem{Event: event.New("test.event", time.Time{}, "x"), Seq: seq}
+	}
+	if err := p.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	if len(ackedSeqs) != n {
+		t.Fatalf("acked %d seqs, want %d: %v", len(ackedSeqs), n, ackedSeqs)
+	}
+	for i, seq := range ackedSeqs {
+		if seq != uint64(i+1) {
+			t.Fatalf("ackedSeqs = %v, want strictly increasing 1..%d", ackedSeqs, n)
+		}
+	}
+}
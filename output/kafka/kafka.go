@@ -0,0 +1,143 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"sync"
+
+	segmentio "github.com/segmentio/kafka-go"
+
+	"github.com/blinklabs-io/snek/event"
+	"github.com/blinklabs-io/snek/output/internal/sink"
+	"github.com/blinklabs-io/snek/pipeline"
+)
+
+type Kafka struct {
+	errorChan    chan error
+	inputChan    chan event.Event
+	seqInputChan chan pipeline.SequencedEvent
+	outputChan   chan event.Event
+	brokers      []string
+	topic        string
+	useCbor      bool
+	processor    *sink.Processor
+	writer       *segmentio.Writer
+	sinkOptions  []sink.ProcessorOptionFunc
+	ackFunc      pipeline.AckFunc
+}
+
+// New returns a new Kafka object with the specified options applied
+func New(options ...KafkaOptionFunc) *Kafka {
+	k := &Kafka{
+		errorChan:    make(chan error),
+		inputChan:    make(chan event.Event, 10),
+		seqInputChan: make(chan pipeline.SequencedEvent, 10),
+	}
+	for _, option := range options {
+		option(k)
+	}
+	return k
+}
+
+// Start the Kafka output
+func (k *Kafka) Start() error {
+	k.writer = &segmentio.Writer{
+		Addr:                   segmentio.TCP(k.brokers...),
+		Topic:                  k.topic,
+		Balancer:               &segmentio.Hash{},
+		AllowAutoTopicCreation: true,
+	}
+	options := append([]sink.ProcessorOptionFunc{sink.WithPluginName("kafka")}, k.sinkOptions...)
+	if k.ackFunc != nil {
+		options = append(options, sink.WithAckFunc(func(seq uint64) { k.ackFunc(seq) }))
+	}
+	k.processor = sink.NewProcessor(k.send, options...)
+	if err := k.processor.Start(); err != nil {
+		return err
+	}
+	// Both forwarding goroutines must drain their channel before the
+	// processor is stopped, or a send on its already-closed SeqInputChan
+	// (or InputChan) can race Stop() and panic
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for evt := range k.inputChan {
+			k.processor.InputChan() <- evt
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for item := range k.seqInputChan {
+			k.processor.SeqInputChan() <- sink.SeqItem{Event: item.Event, Seq: item.Seq}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		_ = k.processor.Stop()
+	}()
+	return nil
+}
+
+// Stop the Kafka output
+func (k *Kafka) Stop() error {
+	close(k.inputChan)
+	close(k.seqInputChan)
+	close(k.errorChan)
+	return k.writer.Close()
+}
+
+// SeqInputChan returns the channel used to feed WAL-sequenced events into
+// the Kafka output, for use by a pipeline.Pipeline configured with a WAL
+func (k *Kafka) SeqInputChan() chan<- pipeline.SequencedEvent {
+	return k.seqInputChan
+}
+
+// SetAckFunc registers the callback invoked with the highest WAL sequence
+// number this output has durably delivered so far
+func (k *Kafka) SetAckFunc(fn pipeline.AckFunc) {
+	k.ackFunc = fn
+}
+
+// ErrorChan returns the output error channel
+func (k *Kafka) ErrorChan() chan error {
+	return k.errorChan
+}
+
+// InputChan returns the input event channel
+func (k *Kafka) InputChan() chan<- event.Event {
+	return k.inputChan
+}
+
+// OutputChan always returns nil
+func (k *Kafka) OutputChan() <-chan event.Event {
+	return nil
+}
+
+func (k *Kafka) send(batch []event.Event) error {
+	msgs := make([]segmentio.Message, len(batch))
+	for i, evt := range batch {
+		data, err := sink.Marshal(evt, k.useCbor)
+		if err != nil {
+			return err
+		}
+		msgs[i] = segmentio.Message{
+			Key:   []byte(sink.PartitionKey(evt)),
+			Value: data,
+		}
+	}
+	return k.writer.WriteMessages(context.Background(), msgs...)
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"time"
+
+	"github.com/blinklabs-io/snek/output/internal/sink"
+)
+
+type KafkaOptionFunc func(*Kafka)
+
+// WithBrokers specifies the Kafka broker addresses to connect to
+func WithBrokers(brokers []string) KafkaOptionFunc {
+	return func(k *Kafka) {
+		k.brokers = brokers
+	}
+}
+
+// WithTopic specifies the Kafka topic to produce events to
+func WithTopic(topic string) KafkaOptionFunc {
+	return func(k *Kafka) {
+		k.topic = topic
+	}
+}
+
+// WithCbor enables CBOR event serialization instead of the default JSON
+func WithCbor(useCbor bool) KafkaOptionFunc {
+	return func(k *Kafka) {
+		k.useCbor = useCbor
+	}
+}
+
+// WithBatchSize sets the number of events to accumulate before producing a batch
+func WithBatchSize(batchSize int) KafkaOptionFunc {
+	return func(k *Kafka) {
+		k.sinkOptions = append(k.sinkOptions, sink.WithBatchSize(batchSize))
+	}
+}
+
+// WithBatchTimeout sets the max time to wait before producing a partial batch
+func WithBatchTimeout(timeout time.Duration) KafkaOptionFunc {
+	return func(k *Kafka) {
+		k.sinkOptions = append(k.sinkOptions, sink.WithBatchTimeout(timeout))
+	}
+}
+
+// WithConcurrency sets the number of batches that may be in flight at once
+func WithConcurrency(concurrency int) KafkaOptionFunc {
+	return func(k *Kafka) {
+		k.sinkOptions = append(k.sinkOptions, sink.WithConcurrency(concurrency))
+	}
+}
+
+// WithMaxRetries sets the number of retry attempts before a batch is dead-lettered
+func WithMaxRetries(maxRetries int) KafkaOptionFunc {
+	return func(k *Kafka) {
+		k.sinkOptions = append(k.sinkOptions, sink.WithMaxRetries(maxRetries))
+	}
+}
+
+// WithDeadLetterPath sets the file that failed batches are appended to
+func WithDeadLetterPath(path string) KafkaOptionFunc {
+	return func(k *Kafka) {
+		k.sinkOptions = append(k.sinkOptions, sink.WithDeadLetterPath(path))
+	}
+}
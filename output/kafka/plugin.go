@@ -0,0 +1,70 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"strings"
+
+	"github.com/blinklabs-io/snek/plugin"
+)
+
+var cmdlineOptions struct {
+	brokers string
+	topic   string
+	useCbor bool
+}
+
+func init() {
+	plugin.Register(
+		plugin.PluginEntry{
+			Type:               plugin.PluginTypeOutput,
+			Name:               "kafka",
+			Description:        "send events to an Apache Kafka topic",
+			NewFromOptionsFunc: NewFromCmdlineOptions,
+			Options: []plugin.PluginOption{
+				{
+					Name:         "brokers",
+					Type:         plugin.PluginOptionTypeString,
+					Description:  "specifies the comma-separated list of Kafka broker addresses",
+					DefaultValue: "localhost:9092",
+					Dest:         &(cmdlineOptions.brokers),
+				},
+				{
+					Name:         "topic",
+					Type:         plugin.PluginOptionTypeString,
+					Description:  "specifies the topic to produce events to",
+					DefaultValue: "snek",
+					Dest:         &(cmdlineOptions.topic),
+				},
+				{
+					Name:         "cbor",
+					Type:         plugin.PluginOptionTypeBool,
+					Description:  "serialize events as CBOR instead of JSON",
+					DefaultValue: false,
+					Dest:         &(cmdlineOptions.useCbor),
+				},
+			},
+		},
+	)
+}
+
+func NewFromCmdlineOptions() plugin.Plugin {
+	p := New(
+		WithBrokers(strings.Split(cmdlineOptions.brokers, ",")),
+		WithTopic(cmdlineOptions.topic),
+		WithCbor(cmdlineOptions.useCbor),
+	)
+	return p
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"sync"
+
+	natsgo "github.com/nats-io/nats.go"
+
+	"github.com/blinklabs-io/snek/event"
+	"github.com/blinklabs-io/snek/output/internal/sink"
+	"github.com/blinklabs-io/snek/pipeline"
+)
+
+type Nats struct {
+	errorChan    chan error
+	inputChan    chan event.Event
+	seqInputChan chan pipeline.SequencedEvent
+	url          string
+	subject      string
+	useCbor      bool
+	processor    *sink.Processor
+	sinkOptions  []sink.ProcessorOptionFunc
+	conn         *natsgo.Conn
+	js           natsgo.JetStreamContext
+	ackFunc      pipeline.AckFunc
+}
+
+// New returns a new Nats object with the specified options applied
+func New(options ...NatsOptionFunc) *Nats {
+	n := &Nats{
+		errorChan:    make(chan error),
+		inputChan:    make(chan event.Event, 10),
+		seqInputChan: make(chan pipeline.SequencedEvent, 10),
+	}
+	for _, option := range options {
+		option(n)
+	}
+	return n
+}
+
+// Start the NATS JetStream output
+func (n *Nats) Start() error {
+	conn, err := natsgo.Connect(n.url)
+	if err != nil {
+		return err
+	}
+	n.conn = conn
+	js, err := conn.JetStream()
+	if err != nil {
+		return err
+	}
+	n.js = js
+	options := append([]sink.ProcessorOptionFunc{sink.WithPluginName("nats")}, n.sinkOptions...)
+	if n.ackFunc != nil {
+		options = append(options, sink.WithAckFunc(func(seq uint64) { n.ackFunc(seq) }))
+	}
+	n.processor = sink.NewProcessor(n.send, options...)
+	if err := n.processor.Start(); err != nil {
+		return err
+	}
+	// Both forwarding goroutines must drain their channel before the
+	// processor is stopped, or a send on its already-closed SeqInputChan
+	// (or InputChan) can race Stop() and panic
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for evt := range n.inputChan {
+			n.processor.InputChan() <- evt
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for item := range n.seqInputChan {
+			n.processor.SeqInputChan() <- sink.SeqItem{Event: item.Event, Seq: item.Seq}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		_ = n.processor.Stop()
+	}()
+	return nil
+}
+
+// Stop the NATS JetStream output
+func (n *Nats) Stop() error {
+	close(n.inputChan)
+	close(n.seqInputChan)
+	close(n.errorChan)
+	n.conn.Close()
+	return nil
+}
+
+// SeqInputChan returns the channel used to feed WAL-sequenced events into
+// the NATS output, for use by a pipeline.Pipeline configured with a WAL
+func (n *Nats) SeqInputChan() chan<- pipeline.SequencedEvent {
+	return n.seqInputChan
+}
+
+// SetAckFunc registers the callback invoked with the highest WAL sequence
+// number this output has durably delivered so far
+func (n *Nats) SetAckFunc(fn pipeline.AckFunc) {
+	n.ackFunc = fn
+}
+
+// ErrorChan returns the output error channel
+func (n *Nats) ErrorChan() chan error {
+	return n.errorChan
+}
+
+// InputChan returns the input event channel
+func (n *Nats) InputChan() chan<- event.Event {
+	return n.inputChan
+}
+
+// OutputChan always returns nil
+func (n *Nats) OutputChan() <-chan event.Event {
+	return nil
+}
+
+func (n *Nats) send(batch []event.Event) error {
+	for _, evt := range batch {
+		data, err := sink.Marshal(evt, n.useCbor)
+		if err != nil {
+			return err
+		}
+		msg := &natsgo.Msg{
+			Subject: n.subject,
+			Data:    data,
+			Header:  natsgo.Header{"Nats-Msg-Id": []string{sink.PartitionKey(evt)}},
+		}
+		if _, err := n.js.PublishMsg(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
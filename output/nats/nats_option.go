@@ -0,0 +1,79 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	"time"
+
+	"github.com/blinklabs-io/snek/output/internal/sink"
+)
+
+type NatsOptionFunc func(*Nats)
+
+// WithUrl specifies the NATS server URL to connect to
+func WithUrl(url string) NatsOptionFunc {
+	return func(n *Nats) {
+		n.url = url
+	}
+}
+
+// WithSubject specifies the subject to publish events to
+func WithSubject(subject string) NatsOptionFunc {
+	return func(n *Nats) {
+		n.subject = subject
+	}
+}
+
+// WithCbor enables CBOR event serialization instead of the default JSON
+func WithCbor(useCbor bool) NatsOptionFunc {
+	return func(n *Nats) {
+		n.useCbor = useCbor
+	}
+}
+
+// WithBatchSize sets the number of events to accumulate before publishing a batch
+func WithBatchSize(batchSize int) NatsOptionFunc {
+	return func(n *Nats) {
+		n.sinkOptions = append(n.sinkOptions, sink.WithBatchSize(batchSize))
+	}
+}
+
+// WithBatchTimeout sets the max time to wait before publishing a partial batch
+func WithBatchTimeout(timeout time.Duration) NatsOptionFunc {
+	return func(n *Nats) {
+		n.sinkOptions = append(n.sinkOptions, sink.WithBatchTimeout(timeout))
+	}
+}
+
+// WithConcurrency sets the number of batches that may be in flight at once
+func WithConcurrency(concurrency int) NatsOptionFunc {
+	return func(n *Nats) {
+		n.sinkOptions = append(n.sinkOptions, sink.WithConcurrency(concurrency))
+	}
+}
+
+// WithMaxRetries sets the number of retry attempts before a batch is dead-lettered
+func WithMaxRetries(maxRetries int) NatsOptionFunc {
+	return func(n *Nats) {
+		n.sinkOptions = append(n.sinkOptions, sink.WithMaxRetries(maxRetries))
+	}
+}
+
+// WithDeadLetterPath sets the file that failed batches are appended to
+func WithDeadLetterPath(path string) NatsOptionFunc {
+	return func(n *Nats) {
+		n.sinkOptions = append(n.sinkOptions, sink.WithDeadLetterPath(path))
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nats
+
+import (
+	natsgo "github.com/nats-io/nats.go"
+
+	"github.com/blinklabs-io/snek/plugin"
+)
+
+var cmdlineOptions struct {
+	url     string
+	subject string
+	useCbor bool
+}
+
+func init() {
+	plugin.Register(
+		plugin.PluginEntry{
+			Type:               plugin.PluginTypeOutput,
+			Name:               "nats",
+			Description:        "send events to a NATS JetStream subject",
+			NewFromOptionsFunc: NewFromCmdlineOptions,
+			Options: []plugin.PluginOption{
+				{
+					Name:         "url",
+					Type:         plugin.PluginOptionTypeString,
+					Description:  "specifies the NATS server URL to connect to",
+					DefaultValue: natsgo.DefaultURL,
+					Dest:         &(cmdlineOptions.url),
+				},
+				{
+					Name:         "subject",
+					Type:         plugin.PluginOptionTypeString,
+					Description:  "specifies the subject to publish events to",
+					DefaultValue: "snek",
+					Dest:         &(cmdlineOptions.subject),
+				},
+				{
+					Name:         "cbor",
+					Type:         plugin.PluginOptionTypeBool,
+					Description:  "serialize events as CBOR instead of JSON",
+					DefaultValue: false,
+					Dest:         &(cmdlineOptions.useCbor),
+				},
+			},
+		},
+	)
+}
+
+func NewFromCmdlineOptions() plugin.Plugin {
+	p := New(
+		WithUrl(cmdlineOptions.url),
+		WithSubject(cmdlineOptions.subject),
+		WithCbor(cmdlineOptions.useCbor),
+	)
+	return p
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redisstreams
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/blinklabs-io/snek/event"
+	"github.com/blinklabs-io/snek/output/internal/sink"
+	"github.com/blinklabs-io/snek/pipeline"
+)
+
+type RedisStreams struct {
+	errorChan    chan error
+	inputChan    chan event.Event
+	seqInputChan chan pipeline.SequencedEvent
+	addr         string
+	stream       string
+	maxLen       int64
+	useCbor      bool
+	processor    *sink.Processor
+	sinkOptions  []sink.ProcessorOptionFunc
+	client       *redis.Client
+	ackFunc      pipeline.AckFunc
+}
+
+// New returns a new RedisStreams object with the specified options applied
+func New(options ...RedisStreamsOptionFunc) *RedisStreams {
+	r := &RedisStreams{
+		errorChan:    make(chan error),
+		inputChan:    make(chan event.Event, 10),
+		seqInputChan: make(chan pipeline.SequencedEvent, 10),
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// Start the Redis Streams output
+func (r *RedisStreams) Start() error {
+	r.client = redis.NewClient(&redis.Options{Addr: r.addr})
+	options := append([]sink.ProcessorOptionFunc{sink.WithPluginName("redisstreams")}, r.sinkOptions...)
+	if r.ackFunc != nil {
+		options = append(options, sink.WithAckFunc(func(seq uint64) { r.ackFunc(seq) }))
+	}
+	r.processor = sink.NewProcessor(r.send, options...)
+	if err := r.processor.Start(); err != nil {
+		return err
+	}
+	// Both forwarding goroutines must drain their channel before the
+	// processor is stopped, or a send on its already-closed SeqInputChan
+	// (or InputChan) can race Stop() and panic
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for evt := range r.inputChan {
+			r.processor.InputChan() <- evt
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for item := range r.seqInputChan {
+			r.processor.SeqInputChan() <- sink.SeqItem{Event: item.Event, Seq: item.Seq}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		_ = r.processor.Stop()
+	}()
+	return nil
+}
+
+// Stop the Redis Streams output
+func (r *RedisStreams) Stop() error {
+	close(r.inputChan)
+	close(r.seqInputChan)
+	close(r.errorChan)
+	return r.client.Close()
+}
+
+// SeqInputChan returns the channel used to feed WAL-sequenced events into
+// the Redis Streams output, for use by a pipeline.Pipeline configured with
+// a WAL
+func (r *RedisStreams) SeqInputChan() chan<- pipeline.SequencedEvent {
+	return r.seqInputChan
+}
+
+// SetAckFunc registers the callback invoked with the highest WAL sequence
+// number this output has durably delivered so far
+func (r *RedisStreams) SetAckFunc(fn pipeline.AckFunc) {
+	r.ackFunc = fn
+}
+
+// ErrorChan returns the output error channel
+func (r *RedisStreams) ErrorChan() chan error {
+	return r.errorChan
+}
+
+// InputChan returns the input event channel
+func (r *RedisStreams) InputChan() chan<- event.Event {
+	return r.inputChan
+}
+
+// OutputChan always returns nil
+func (r *RedisStreams) OutputChan() <-chan event.Event {
+	return nil
+}
+
+func (r *RedisStreams) send(batch []event.Event) error {
+	ctx := context.Background()
+	pipe := r.client.Pipeline()
+	for _, evt := range batch {
+		data, err := sink.Marshal(evt, r.useCbor)
+		if err != nil {
+			return err
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: r.stream,
+			MaxLen: r.maxLen,
+			Approx: r.maxLen > 0,
+			Values: map[string]interface{}{
+				"key":  sink.PartitionKey(evt),
+				"data": data,
+			},
+		})
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
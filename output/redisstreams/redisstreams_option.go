@@ -0,0 +1,87 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redisstreams
+
+import (
+	"time"
+
+	"github.com/blinklabs-io/snek/output/internal/sink"
+)
+
+type RedisStreamsOptionFunc func(*RedisStreams)
+
+// WithAddr specifies the Redis server address to connect to
+func WithAddr(addr string) RedisStreamsOptionFunc {
+	return func(r *RedisStreams) {
+		r.addr = addr
+	}
+}
+
+// WithStream specifies the stream key to append events to
+func WithStream(stream string) RedisStreamsOptionFunc {
+	return func(r *RedisStreams) {
+		r.stream = stream
+	}
+}
+
+// WithMaxLen caps the stream length, trimming oldest entries as new ones are
+// added. A value of 0 (the default) disables trimming
+func WithMaxLen(maxLen int64) RedisStreamsOptionFunc {
+	return func(r *RedisStreams) {
+		r.maxLen = maxLen
+	}
+}
+
+// WithCbor enables CBOR event serialization instead of the default JSON
+func WithCbor(useCbor bool) RedisStreamsOptionFunc {
+	return func(r *RedisStreams) {
+		r.useCbor = useCbor
+	}
+}
+
+// WithBatchSize sets the number of events to accumulate before pipelining a batch
+func WithBatchSize(batchSize int) RedisStreamsOptionFunc {
+	return func(r *RedisStreams) {
+		r.sinkOptions = append(r.sinkOptions, sink.WithBatchSize(batchSize))
+	}
+}
+
+// WithBatchTimeout sets the max time to wait before pipelining a partial batch
+func WithBatchTimeout(timeout time.Duration) RedisStreamsOptionFunc {
+	return func(r *RedisStreams) {
+		r.sinkOptions = append(r.sinkOptions, sink.WithBatchTimeout(timeout))
+	}
+}
+
+// WithConcurrency sets the number of batches that may be in flight at once
+func WithConcurrency(concurrency int) RedisStreamsOptionFunc {
+	return func(r *RedisStreams) {
+		r.sinkOptions = append(r.sinkOptions, sink.WithConcurrency(concurrency))
+	}
+}
+
+// WithMaxRetries sets the number of retry attempts before a batch is dead-lettered
+func WithMaxRetries(maxRetries int) RedisStreamsOptionFunc {
+	return func(r *RedisStreams) {
+		r.sinkOptions = append(r.sinkOptions, sink.WithMaxRetries(maxRetries))
+	}
+}
+
+// WithDeadLetterPath sets the file that failed batches are appended to
+func WithDeadLetterPath(path string) RedisStreamsOptionFunc {
+	return func(r *RedisStreams) {
+		r.sinkOptions = append(r.sinkOptions, sink.WithDeadLetterPath(path))
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/blinklabs-io/snek/event"
+)
+
+// fileWAL is a WAL backed by a single append-only, newline-delimited JSON
+// log file plus a small sibling file tracking the committed sequence
+// number. Once the log grows past maxSizeBytes, it's rewritten to drop
+// entries at or below the committed sequence number
+type fileWAL struct {
+	mutex        sync.Mutex
+	path         string
+	maxSizeBytes int64
+	file         *os.File
+	nextSeq      uint64
+	committedSeq uint64
+}
+
+// openFileWAL opens (creating if necessary) the log file at path, recovering
+// the next sequence number and committed cursor from whatever was already
+// on disk
+func openFileWAL(path string, maxSizeBytes int64) (*fileWAL, error) {
+	w := &fileWAL{path: path, maxSizeBytes: maxSizeBytes, nextSeq: 1}
+	if err := w.loadCommitted(); err != nil {
+		return nil, err
+	}
+	if err := w.recoverNextSeq(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	w.file = f
+	return w, nil
+}
+
+func (w *fileWAL) committedPath() string {
+	return w.path + ".committed"
+}
+
+func (w *fileWAL) loadCommitted() error {
+	data, err := os.ReadFile(w.committedPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var seq uint64
+	if _, err := fmt.Sscanf(string(data), "%d", &seq); err != nil {
+		return err
+	}
+	w.committedSeq = seq
+	return nil
+}
+
+func (w *fileWAL) recoverNextSeq() error {
+	f, err := os.Open(w.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if e.Seq >= w.nextSeq {
+			w.nextSeq = e.Seq + 1
+		}
+	}
+	return scanner.Err()
+}
+
+func (w *fileWAL) Append(evt event.Event) (uint64, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	seq := w.nextSeq
+	w.nextSeq++
+	data, err := json.Marshal(Entry{Seq: seq, Event: evt})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.file.Write(append(data, '\n')); err != nil {
+		return 0, err
+	}
+	return seq, w.file.Sync()
+}
+
+func (w *fileWAL) Replay(fn func(Entry) error) error {
+	w.mutex.Lock()
+	committed := w.committedSeq
+	w.mutex.Unlock()
+	f, err := os.Open(w.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return err
+		}
+		if e.Seq <= committed {
+			continue
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (w *fileWAL) Commit(seq uint64) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if seq <= w.committedSeq {
+		return nil
+	}
+	w.committedSeq = seq
+	tmp := w.committedPath() + ".tmp"
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%d", seq)), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, w.committedPath()); err != nil {
+		return err
+	}
+	return w.compactLocked()
+}
+
+// compactLocked rewrites the log file to drop entries at or below the
+// committed sequence number, once the file has grown past maxSizeBytes.
+// Callers must hold w.mutex. A maxSizeBytes of 0 disables compaction
+func (w *fileWAL) compactLocked() error {
+	if w.maxSizeBytes <= 0 {
+		return nil
+	}
+	info, err := w.file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < w.maxSizeBytes {
+		return nil
+	}
+	tmpPath := w.path + ".compact"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(0, 0); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	scanner := bufio.NewScanner(w.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		line := scanner.Bytes()
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		if e.Seq <= w.committedSeq {
+			continue
+		}
+		if _, err := fmt.Fprintf(tmpFile, "%s\n", line); err != nil {
+			tmpFile.Close()
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, w.path); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	w.file = f
+	return nil
+}
+
+func (w *fileWAL) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.file.Close()
+}
@@ -0,0 +1,182 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/snek/event"
+)
+
+func testEvent(payload string) event.Event {
+	return event.New("test.event", time.Time{}, payload)
+}
+
+func TestFileWALAppendAssignsIncreasingSeq(t *testing.T) {
+	w, err := openFileWAL(filepath.Join(t.TempDir(), "wal.jsonl"), 0)
+	if err != nil {
+		t.Fatalf("openFileWAL failed: %v", err)
+	}
+	defer w.Close()
+	for i, want := range []uint64{1, 2, 3} {
+		seq, err := w.Append(testEvent("a"))
+		if err != nil {
+			t.Fatalf("Append #%d failed: %v", i, err)
+		}
+		if seq != want {
+			t.Fatalf("Append #%d seq = %d, want %d", i, seq, want)
+		}
+	}
+}
+
+func TestFileWALReplaySkipsCommitted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal.jsonl")
+	w, err := openFileWAL(path, 0)
+	if err != nil {
+		t.Fatalf("openFileWAL failed: %v", err)
+	}
+	defer w.Close()
+
+	var seqs []uint64
+	for _, payload := range []string{"a", "b", "c"} {
+		seq, err := w.Append(testEvent(payload))
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		seqs = append(seqs, seq)
+	}
+	if err := w.Commit(seqs[0]); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	var replayed []uint64
+	err = w.Replay(func(e Entry) error {
+		replayed = append(replayed, e.Seq)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(replayed) != 2 || replayed[0] != seqs[1] || replayed[1] != seqs[2] {
+		t.Fatalf("Replay returned seqs %v, want %v", replayed, seqs[1:])
+	}
+}
+
+func TestFileWALRecoversStateAfterReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal.jsonl")
+	w, err := openFileWAL(path, 0)
+	if err != nil {
+		t.Fatalf("openFileWAL failed: %v", err)
+	}
+	var lastSeq uint64
+	for _, payload := range []string{"a", "b"} {
+		seq, err := w.Append(testEvent(payload))
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		lastSeq = seq
+	}
+	if err := w.Commit(lastSeq - 1); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := openFileWAL(path, 0)
+	if err != nil {
+		t.Fatalf("re-open failed: %v", err)
+	}
+	defer reopened.Close()
+
+	seq, err := reopened.Append(testEvent("c"))
+	if err != nil {
+		t.Fatalf("Append after reopen failed: %v", err)
+	}
+	if seq != lastSeq+1 {
+		t.Fatalf("Append after reopen seq = %d, want %d", seq, lastSeq+1)
+	}
+
+	var replayed []uint64
+	if err := reopened.Replay(func(e Entry) error {
+		replayed = append(replayed, e.Seq)
+		return nil
+	}); err != nil {
+		t.Fatalf("Replay after reopen failed: %v", err)
+	}
+	if len(replayed) != 2 || replayed[0] != lastSeq || replayed[1] != seq {
+		t.Fatalf("Replay after reopen = %v, want [%d %d]", replayed, lastSeq, seq)
+	}
+}
+
+func TestFileWALCompactsCommittedEntriesOnCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal.jsonl")
+	// maxSizeBytes of 1 forces compaction on every Commit once the file is
+	// non-empty
+	w, err := openFileWAL(path, 1)
+	if err != nil {
+		t.Fatalf("openFileWAL failed: %v", err)
+	}
+	defer w.Close()
+
+	var seqs []uint64
+	for _, payload := range []string{"a", "b", "c"} {
+		seq, err := w.Append(testEvent(payload))
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		seqs = append(seqs, seq)
+	}
+	if err := w.Commit(seqs[1]); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	var replayed []uint64
+	if err := w.Replay(func(e Entry) error {
+		replayed = append(replayed, e.Seq)
+		return nil
+	}); err != nil {
+		t.Fatalf("Replay after compaction failed: %v", err)
+	}
+	if len(replayed) != 1 || replayed[0] != seqs[2] {
+		t.Fatalf("Replay after compaction = %v, want [%d]", replayed, seqs[2])
+	}
+}
+
+func TestFileWALCommitIsIdempotentForOldSeq(t *testing.T) {
+	w, err := openFileWAL(filepath.Join(t.TempDir(), "wal.jsonl"), 0)
+	if err != nil {
+		t.Fatalf("openFileWAL failed: %v", err)
+	}
+	defer w.Close()
+
+	seq, err := w.Append(testEvent("a"))
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := w.Commit(seq); err != nil {
+		t.Fatalf("first Commit failed: %v", err)
+	}
+	// A Commit for an already-committed (or older) seq must be a no-op, not
+	// an error
+	if err := w.Commit(seq); err != nil {
+		t.Fatalf("second Commit failed: %v", err)
+	}
+	if err := w.Commit(0); err != nil {
+		t.Fatalf("Commit(0) failed: %v", err)
+	}
+}
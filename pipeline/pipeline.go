@@ -0,0 +1,191 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pipeline provides an optional, WAL-backed fan-out stage between
+// an input stage's output channel and one or more downstream output
+// plugins. Without a WAL configured (see WithWAL) it's a thin fan-out: a
+// slow or crashed output only ever loses what was sitting unbuffered in its
+// own input channel, same as wiring outputs directly off the input stage's
+// OutputChan. With a WAL configured, every event is durably logged and
+// assigned a sequence number before being fanned out, and an output that
+// implements AckingSink only has its events dropped from the log once it's
+// acknowledged them, so a crash replays whatever wasn't yet fully delivered
+package pipeline
+
+import (
+	"sync"
+
+	"github.com/blinklabs-io/snek/event"
+)
+
+// Sink is the subset of plugin.Plugin that Pipeline needs in order to fan
+// an event out to a downstream output stage
+type Sink interface {
+	InputChan() chan<- event.Event
+}
+
+// SequencedEvent pairs an event with the WAL sequence number it was
+// assigned, for delivery to an AckingSink
+type SequencedEvent struct {
+	Seq   uint64
+	Event event.Event
+}
+
+// AckFunc reports that a Sink has durably delivered every event it was
+// handed up to and including the one assigned WAL sequence number seq
+type AckFunc func(seq uint64)
+
+// AckingSink is implemented by output plugins that participate in
+// WAL-backed at-least-once delivery. A Sink that doesn't also implement
+// AckingSink is still wired into the pipeline, but its deliveries remain
+// fire-and-forget and never advance the WAL's committed cursor
+type AckingSink interface {
+	// SeqInputChan returns the channel used to hand the sink events
+	// together with their WAL sequence number, in place of InputChan
+	SeqInputChan() chan<- SequencedEvent
+	// SetAckFunc registers the callback the sink invokes with the highest
+	// WAL sequence number it has durably delivered so far. Called once,
+	// before Start
+	SetAckFunc(fn AckFunc)
+}
+
+// Pipeline reads events from a single input channel and fans each one out
+// to every configured Sink
+type Pipeline struct {
+	wal     WAL
+	walErr  error
+	input   <-chan event.Event
+	outputs []Sink
+
+	errorChan chan error
+	doneChan  chan struct{}
+
+	mutex sync.Mutex
+	acked []uint64 // per-acking-output: highest seq acknowledged so far
+}
+
+// New returns a new Pipeline with the specified options applied
+func New(options ...PipelineOptionFunc) *Pipeline {
+	p := &Pipeline{
+		errorChan: make(chan error),
+		doneChan:  make(chan struct{}),
+	}
+	for _, option := range options {
+		option(p)
+	}
+	for _, out := range p.outputs {
+		if acking, ok := out.(AckingSink); ok {
+			idx := len(p.acked)
+			p.acked = append(p.acked, 0)
+			acking.SetAckFunc(p.makeAckFunc(idx))
+		}
+	}
+	return p
+}
+
+// Start begins replaying any WAL entries left over from a previous run,
+// then fans out events read from the input channel until it's closed
+func (p *Pipeline) Start() error {
+	if p.walErr != nil {
+		return p.walErr
+	}
+	if p.wal != nil {
+		if err := p.wal.Replay(func(e Entry) error {
+			p.dispatch(e.Seq, e.Event)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	go p.run()
+	return nil
+}
+
+// Stop closes the WAL, if one is configured. It does not close the input
+// channel or any output's input channel; those are owned by the respective
+// stages
+func (p *Pipeline) Stop() error {
+	if p.wal != nil {
+		return p.wal.Close()
+	}
+	return nil
+}
+
+// ErrorChan returns the pipeline's error channel
+func (p *Pipeline) ErrorChan() chan error {
+	return p.errorChan
+}
+
+// DoneChan is closed once the input channel has closed and every event has
+// been dispatched
+func (p *Pipeline) DoneChan() <-chan struct{} {
+	return p.doneChan
+}
+
+func (p *Pipeline) run() {
+	defer close(p.doneChan)
+	for evt := range p.input {
+		var seq uint64
+		if p.wal != nil {
+			var err error
+			seq, err = p.wal.Append(evt)
+			if err != nil {
+				p.errorChan <- err
+				continue
+			}
+		}
+		p.dispatch(seq, evt)
+	}
+}
+
+func (p *Pipeline) dispatch(seq uint64, evt event.Event) {
+	for _, out := range p.outputs {
+		if acking, ok := out.(AckingSink); ok {
+			acking.SeqInputChan() <- SequencedEvent{Seq: seq, Event: evt}
+			continue
+		}
+		out.InputChan() <- evt
+	}
+}
+
+// makeAckFunc returns the AckFunc wired into the idx'th AckingSink output.
+// The WAL's committed cursor only advances to the minimum sequence number
+// acknowledged across all AckingSink outputs, so a crash replays anything
+// a slower output hadn't yet confirmed. A pipeline with a WAL but no
+// AckingSink outputs never advances past sequence 0 and the log grows
+// without bound; pair WithWAL with at least one acknowledging output
+func (p *Pipeline) makeAckFunc(idx int) AckFunc {
+	return func(seq uint64) {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		if seq > p.acked[idx] {
+			p.acked[idx] = seq
+		}
+		if p.wal == nil || len(p.acked) == 0 {
+			return
+		}
+		min := p.acked[0]
+		for _, s := range p.acked[1:] {
+			if s < min {
+				min = s
+			}
+		}
+		if err := p.wal.Commit(min); err != nil {
+			select {
+			case p.errorChan <- err:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import "github.com/blinklabs-io/snek/event"
+
+type PipelineOptionFunc func(*Pipeline)
+
+// WithInput sets the channel the pipeline reads events from, typically an
+// input stage's OutputChan()
+func WithInput(input <-chan event.Event) PipelineOptionFunc {
+	return func(p *Pipeline) {
+		p.input = input
+	}
+}
+
+// WithOutputs sets the downstream sinks events are fanned out to
+func WithOutputs(outputs ...Sink) PipelineOptionFunc {
+	return func(p *Pipeline) {
+		p.outputs = outputs
+	}
+}
+
+// WithWAL enables a persistent write-ahead log at path: every event read
+// from the input is durably appended and assigned a sequence number before
+// being fanned out, so a crash before every AckingSink output has
+// acknowledged it is replayed on the next Start. The log is compacted once
+// it grows past maxSizeBytes and its entries have been acknowledged; a
+// maxSizeBytes of 0 disables compaction
+func WithWAL(path string, maxSizeBytes int64) PipelineOptionFunc {
+	return func(p *Pipeline) {
+		wal, err := openFileWAL(path, maxSizeBytes)
+		if err != nil {
+			// Surfaced the first time Start is called, consistent with how
+			// the rest of the option funcs can't themselves return an error
+			p.walErr = err
+			return
+		}
+		p.wal = wal
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 Blink Labs, LLC.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import "github.com/blinklabs-io/snek/event"
+
+// Entry is a single WAL record: the sequence number the WAL assigned an
+// event, alongside the event itself
+type Entry struct {
+	Seq   uint64      `json:"seq"`
+	Event event.Event `json:"event"`
+}
+
+// WAL durably records events in the order they're read off a Pipeline's
+// input, so that events already accepted but not yet acknowledged by every
+// output survive a crash and can be replayed on restart
+type WAL interface {
+	// Append durably writes evt and returns the sequence number assigned to it
+	Append(evt event.Event) (uint64, error)
+	// Replay invokes fn, in order, for every entry with a sequence number
+	// greater than the last committed one
+	Replay(fn func(Entry) error) error
+	// Commit records seq as the highest sequence number every output has
+	// acknowledged. Entries at or below seq become eligible for removal on
+	// the next compaction
+	Commit(seq uint64) error
+	// Close releases any resources held by the WAL
+	Close() error
+}